@@ -0,0 +1,133 @@
+// Package charset detects the legacy 8-bit encodings older subtitles are written
+// in and converts them to UTF-8.
+package charset
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// Encoding identifies the character encoding of a text file.
+type Encoding int
+
+const (
+	Unknown     Encoding = iota // binary data, UTF-16 or anything else left alone
+	UTF8                        // valid UTF-8 (plain ASCII included)
+	Windows1252                 // Western European
+	Windows1251                 // Cyrillic
+)
+
+// String returns the IANA name of the encoding.
+func (e Encoding) String() string {
+	switch e {
+	case UTF8:
+		return "utf-8"
+	case Windows1252:
+		return "windows-1252"
+	case Windows1251:
+		return "windows-1251"
+	}
+	return "unknown"
+}
+
+// BackupSuffix is appended to the name of the original file ConvertFile keeps.
+const BackupSuffix = ".bak"
+
+// cyrillicShare is the share of high letters (0xC0 and above) directly following
+// another high letter from which text is taken to be Cyrillic. Windows-1251 words
+// are runs of high letters, while Western text only uses the range for scattered
+// accented letters.
+const cyrillicShare = 0.5
+
+// Detect guesses the encoding of text data. Valid UTF-8 wins; data holding NUL
+// bytes (binary subtitles, UTF-16) is Unknown; otherwise how often high letters
+// follow each other decides between Windows-1251 and Windows-1252.
+func Detect(data []byte) Encoding {
+	if utf8.Valid(data) {
+		return UTF8
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return Unknown
+	}
+	high, runs := 0, 0
+	for i, b := range data {
+		if b < 0xC0 {
+			continue
+		}
+		high++
+		if i > 0 && data[i-1] >= 0xC0 {
+			runs++
+		}
+	}
+	if high > 0 && float64(runs) >= cyrillicShare*float64(high) {
+		return Windows1251
+	}
+	return Windows1252
+}
+
+// Decode returns data decoded from e as a UTF-8 string. UTF-8 and Unknown data
+// is returned as is.
+func Decode(data []byte, e Encoding) string {
+	var table *[128]rune
+	switch e {
+	case Windows1252:
+		table = &windows1252
+	case Windows1251:
+		table = &windows1251
+	default:
+		return string(data)
+	}
+	var b strings.Builder
+	b.Grow(len(data) + len(data)/2)
+	for _, c := range data {
+		if c < 0x80 {
+			b.WriteByte(c)
+		} else {
+			b.WriteRune(table[c-0x80])
+		}
+	}
+	return b.String()
+}
+
+// ConvertFile rewrites the file at path in UTF-8 when it is in a legacy encoding,
+// first saving the original as path+BackupSuffix. It returns the encoding the file
+// was converted from, or UTF8/Unknown when nothing was done. An existing backup is
+// never overwritten.
+func ConvertFile(path string) (Encoding, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Unknown, err
+	}
+	enc := Detect(data)
+	if enc != Windows1252 && enc != Windows1251 {
+		return enc, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return enc, err
+	}
+	backup, err := os.OpenFile(path+BackupSuffix, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return enc, fmt.Errorf("backup: %w", err)
+	}
+	if _, err := backup.Write(data); err != nil {
+		backup.Close()
+		return enc, fmt.Errorf("backup: %w", err)
+	}
+	if err := backup.Close(); err != nil {
+		return enc, fmt.Errorf("backup: %w", err)
+	}
+	tmp := path + ".utf8.tmp"
+	if err := os.WriteFile(tmp, []byte(Decode(data, enc)), info.Mode().Perm()); err != nil {
+		os.Remove(tmp)
+		return enc, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return enc, err
+	}
+	return enc, nil
+}
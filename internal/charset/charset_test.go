@@ -0,0 +1,65 @@
+package charset
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		in   []byte
+		want Encoding
+	}{
+		{"ascii", []byte("Hello there.\n"), UTF8},
+		{"utf-8", []byte("Déjà vu.\n"), UTF8},
+		{"windows-1252", []byte("D\xe9j\xe0 vu, ma ch\xe8re.\n"), Windows1252},
+		{"windows-1251", []byte("\xcf\xf0\xe8\xe2\xe5\xf2, \xea\xe0\xea \xe4\xe5\xeb\xe0?\n"), Windows1251},
+		{"quotes only", []byte("\x93Hello\x94\n"), Windows1252},
+		{"binary", []byte("\x00\x00\x01\xba\xff\xfe"), Unknown},
+	}
+	for _, tc := range tests {
+		if got := Detect(tc.in); got != tc.want {
+			t.Errorf("Detect(%s) = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+	if got := Decode([]byte("\xcf\xf0\xe8\xe2\xe5\xf2 \xa8\xb8"), Windows1251); got != "Привет Ёё" {
+		t.Errorf("Decode(windows-1251) = %q, want %q", got, "Привет Ёё")
+	}
+	if got := Decode([]byte("\x93Caf\xe9\x94 \x80"), Windows1252); got != "“Café” €" {
+		t.Errorf("Decode(windows-1252) = %q, want %q", got, "“Café” €")
+	}
+}
+
+func TestConvertFile(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "movie.srt")
+	original := []byte("1\n00:00:01,000 --> 00:00:02,000\nD\xe9j\xe0 vu.\n")
+	os.WriteFile(path, original, 0644)
+
+	enc, err := ConvertFile(path)
+	if err != nil || enc != Windows1252 {
+		t.Fatalf("ConvertFile() = (%v, %v), want windows-1252", enc, err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "1\n00:00:01,000 --> 00:00:02,000\nDéjà vu.\n" {
+		t.Errorf("ConvertFile() wrote %q", got)
+	}
+	if got, _ := os.ReadFile(path + BackupSuffix); string(got) != string(original) {
+		t.Errorf("ConvertFile() backup = %q, want the original", got)
+	}
+
+	if enc, err := ConvertFile(path); err != nil || enc != UTF8 {
+		t.Errorf("ConvertFile(converted) = (%v, %v), want utf-8 and no change", enc, err)
+	}
+	os.WriteFile(path, original, 0644)
+	if _, err := ConvertFile(path); err == nil {
+		t.Errorf("ConvertFile() overwrote an existing backup")
+	}
+}
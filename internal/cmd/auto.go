@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/media"
+	"github.com/Digital-Shane/treeview"
+)
+
+// AutoCommand indexes as deep as the shows command and lets every top-level entry
+// pick its own mode with Classify, so mixed libraries are handled in one pass.
+// The chosen mode is shown in the tree and can be cycled with the "m" key.
+var AutoCommand = CommandConfig{
+	maxDepth:    3,
+	deepScan:    true,
+	includeDirs: true,
+	preprocess:  AutoPreprocess,
+	annotate:    AutoAnnotate,
+	autoMode:    true,
+}
+
+// autoCycle is the order the "m" key steps through.
+var autoCycle = []Kind{KindMovie, KindShow, KindSeason, KindEpisode}
+
+// AutoPreprocess wraps loose movie files (and their companion files) into virtual
+// directories exactly like the movies command, and groups loose episodes into
+// virtual show folders like the episodes command, leaving every other entry as is.
+func AutoPreprocess(nodes []*treeview.Node[treeview.FileInfo]) []*treeview.Node[treeview.FileInfo] {
+	var movies, episodes, rest []*treeview.Node[treeview.FileInfo]
+	for _, n := range nodes {
+		if n.Data().IsDir() {
+			rest = append(rest, n)
+			continue
+		}
+		switch kind := Classify(n); {
+		case kind == KindMovie, kind == KindUnknown && media.IsCompanion(n.Name()):
+			movies = append(movies, n)
+		case kind == KindEpisode:
+			episodes = append(episodes, n)
+		default:
+			rest = append(rest, n)
+		}
+	}
+	rest = append(rest, MoviePreprocess(movies)...)
+	return append(rest, EpisodePreprocess(episodes)...)
+}
+
+// AutoAnnotate classifies each top-level entry and annotates it with the naming
+// rules of the matching command. Entries without media are left untouched.
+func AutoAnnotate(t *treeview.Tree[treeview.FileInfo]) {
+	for _, n := range t.Nodes() {
+		if kind := Classify(n); kind != KindUnknown {
+			annotateAs(n, kind)
+		}
+	}
+}
+
+// ReclassifyEntry switches a top-level entry to the next mode in the cycle. The
+// entry is indexed again from disk and planned with every BuildPlan pass of cfg
+// in that mode, so placement, collections and folders dropped by an earlier mode
+// are all accounted for; the rebuilt nodes replace n in the tree. Virtual movie
+// folders are fixed to the movies mode and returned as they are.
+func ReclassifyEntry(cfg CommandConfig, n *treeview.Node[treeview.FileInfo]) ([]*treeview.Node[treeview.FileInfo], error) {
+	mm := core.GetMeta(n)
+	if mm != nil && mm.IsVirtual {
+		return []*treeview.Node[treeview.FileInfo]{n}, nil
+	}
+	next := autoCycle[0]
+	if mm != nil {
+		for i, k := range autoCycle {
+			if string(k) == mm.Mode {
+				next = autoCycle[(i+1)%len(autoCycle)]
+			}
+		}
+	}
+
+	nodes, err := IndexEntries(cfg, []string{n.Data().Path})
+	if err != nil {
+		return nil, err
+	}
+	mode, _ := next.Config()
+	c := cfg.withMode(mode)
+	c.annotate = func(t *treeview.Tree[treeview.FileInfo]) {
+		for _, e := range t.Nodes() {
+			annotateAs(e, next)
+		}
+	}
+	return BuildPlan(c, nodes).Nodes(), nil
+}
+
+// annotateAs applies the annotate pass of kind's command to the subtree rooted at
+// n, ignoring nodes deeper than that command would have indexed, and records the
+// mode on n. Deep scanning modes see the whole subtree.
+func annotateAs(n *treeview.Node[treeview.FileInfo], kind Kind) {
+	mode, ok := kind.Config()
+	if !ok {
+		return
+	}
+	sub := treeview.NewTree([]*treeview.Node[treeview.FileInfo]{n})
+	mode.annotate(sub)
+	// Episode folders still need their files renamed, so allow one level of nesting.
+	levels := max(mode.maxDepth, 2)
+	for ni := range sub.All(context.Background()) {
+		if !mode.deepScan && ni.Depth >= levels && ni.Node.Data().Extra != nil {
+			delete(ni.Node.Data().Extra, "meta")
+		}
+	}
+	core.EnsureMeta(n).Mode = string(kind)
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/treeview"
+)
+
+func TestAutoAnnotateMixedLibrary(t *testing.T) {
+	show := testNewDirNode("Show.Name.2019")
+	season := testNewDirNode("season.1")
+	showEp := testNewFileNode("Show.Name.S01E01.mkv")
+	season.AddChild(showEp)
+	show.AddChild(season)
+
+	pack := testNewDirNode("Other.Show.S02.1080p")
+	packEp := testNewFileNode("Other.Show.S02E05.mkv")
+	pack.AddChild(packEp)
+	pack.AddChild(testNewFileNode("Other.Show.S02E06.mkv"))
+
+	episode := testNewFileNode("Loose.Show.S03E04.mkv")
+	movie := testNewFileNode("Some.Movie.2020.1080p.mkv")
+	notes := testNewFileNode("notes.txt")
+
+	nodes := AutoPreprocess([]*treeview.Node[treeview.FileInfo]{show, pack, episode, movie, notes})
+	tr := testNewTree(nodes...)
+	AutoAnnotate(tr)
+
+	for _, tc := range []struct {
+		node     *treeview.Node[treeview.FileInfo]
+		wantName string
+	}{
+		{show, "Show Name (2019)"},
+		{season, "Season 01"},
+		{showEp, "S01E01.mkv"},
+		{pack, "Season 02"},
+		{packEp, "S02E05.mkv"},
+		{episode, "S03E04.mkv"},
+		{movie, "Some Movie (2020).mkv"},
+	} {
+		mm := core.GetMeta(tc.node)
+		if mm == nil || mm.NewName != tc.wantName {
+			t.Errorf("AutoAnnotate(%s) NewName = %v, want %q", tc.node.Name(), mm, tc.wantName)
+		}
+	}
+	for node, want := range map[*treeview.Node[treeview.FileInfo]]Kind{show: KindShow, pack: KindSeason, episode.Parent().Parent(): KindShow, movie.Parent(): KindMovie} {
+		if got := core.GetMeta(node).Mode; got != string(want) {
+			t.Errorf("AutoAnnotate(%s) Mode = %q, want %q", node.Name(), got, want)
+		}
+	}
+	if core.GetMeta(notes) != nil {
+		t.Errorf("AutoAnnotate annotated an entry without media")
+	}
+}
+
+func TestReclassifyEntry(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	for _, f := range []string{
+		"Alien.1979/Alien.1979.S01E01.mkv",
+		"Alien.1979/Alien.1979.S01E02.mkv",
+		"Alien.1979/Alien.1979.S01E01.nfo",
+	} {
+		os.MkdirAll(filepath.Dir(f), 0755)
+		os.WriteFile(f, []byte("data"), 0644)
+	}
+	cfg := AutoCommand
+	cfg.DeleteNFO = true
+	cfg.Collections = true
+	cfg.CollectionMap = CollectionMap{"alien": "Alien Collection"}
+
+	indexed, err := IndexTree(cfg, ".")
+	if err != nil {
+		t.Fatalf("IndexTree() error = %v", err)
+	}
+	entry := BuildPlan(cfg, UnwrapRoot(indexed)).Nodes()[0]
+	if got := core.GetMeta(entry).Mode; got != string(KindSeason) {
+		t.Fatalf("BuildPlan(Alien.1979) Mode = %q, want seasons", got)
+	}
+
+	nodes, err := ReclassifyEntry(cfg, entry)
+	if err != nil || len(nodes) != 1 {
+		t.Fatalf("ReclassifyEntry(episodes) = %d nodes, %v; want 1 node", len(nodes), err)
+	}
+	entry = nodes[0]
+	tr := testNewTree(entry)
+	mm := core.GetMeta(entry)
+	if mm.Mode != string(KindEpisode) || mm.NewName != "" {
+		t.Errorf("ReclassifyEntry(episodes) folder = %q/%q, want episodes and unchanged", mm.Mode, mm.NewName)
+	}
+	if got := core.GetMeta(findNodeByName(tr, "Alien.1979.S01E01.mkv")).NewName; got != "S01E01.mkv" {
+		t.Errorf("ReclassifyEntry(episodes) episode NewName = %q, want S01E01.mkv", got)
+	}
+	if nfo := findNodeByName(tr, "Alien.1979.S01E01.nfo"); !core.GetMeta(nfo).MarkedForDeletion {
+		t.Errorf("ReclassifyEntry() dropped the deletion mark from the NFO")
+	}
+
+	// The movies mode groups the rebuilt entry into its collection.
+	nodes, err = ReclassifyEntry(cfg, entry)
+	if err != nil || len(nodes) != 1 {
+		t.Fatalf("ReclassifyEntry(movies) = %d nodes, %v; want 1 node", len(nodes), err)
+	}
+	if cm := core.GetMeta(nodes[0]); cm.Type != core.MediaCollection || cm.NewName != "Alien Collection" {
+		t.Fatalf("ReclassifyEntry(movies) = %#v, want the Alien Collection", cm)
+	}
+	movie := nodes[0].Children()[0]
+	if got := core.GetMeta(movie); got.Mode != string(KindMovie) || got.NewName != "Alien (1979)" {
+		t.Errorf("ReclassifyEntry(movies) = %q/%q, want movies/%q", got.Mode, got.NewName, "Alien (1979)")
+	}
+	if len(movie.Children()) != 3 {
+		t.Errorf("ReclassifyEntry(movies) children = %d, want the 3 files back from disk", len(movie.Children()))
+	}
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"github.com/Digital-Shane/title-tidy/internal/media"
+	"github.com/Digital-Shane/treeview"
+)
+
+// Kind names the rename command suited to an entry. The values match the
+// subcommand names so they can be shown and parsed as-is.
+type Kind string
+
+const (
+	KindUnknown Kind = ""
+	KindShow    Kind = "shows"
+	KindSeason  Kind = "seasons"
+	KindEpisode Kind = "episodes"
+	KindMovie   Kind = "movies"
+)
+
+// Config returns the command configuration that names entries of kind k, or
+// false for KindUnknown.
+func (k Kind) Config() (CommandConfig, bool) {
+	switch k {
+	case KindShow:
+		return ShowsCommand, true
+	case KindSeason:
+		return SeasonsCommand, true
+	case KindEpisode:
+		return EpisodesCommand, true
+	case KindMovie:
+		return MoviesCommand, true
+	}
+	return CommandConfig{}, false
+}
+
+// Classify inspects an indexed top-level entry and its descendants to decide
+// which command names it correctly:
+//   - a folder with season sub-folders (Season 1, S02, Specials) is a show;
+//   - a folder where most videos carry an SxxExx marker is a season, or an
+//     episode when it holds a single video, or a show when the markers name
+//     more than one season;
+//   - any other folder with a video, or with a DVD/Blu-ray structure, is a movie;
+//   - loose videos are episodes when they carry a marker, movies otherwise;
+//     loose subtitles and other companion files with a marker are episodes too.
+//
+// Samples are ignored throughout. Other entries without videos are KindUnknown.
+func Classify(n *treeview.Node[treeview.FileInfo]) Kind {
+	if !n.Data().IsDir() {
+		switch {
+		case media.IsCompanion(n.Name()) && media.HasEpisodeMarker(n.Name()):
+			return KindEpisode
+		case !media.IsVideo(n.Name()) || media.IsSample(n.Name()):
+			return KindUnknown
+		case media.HasEpisodeMarker(n.Name()):
+			return KindEpisode
+		}
+		return KindMovie
+	}
+	for _, c := range n.Children() {
+		if c.Data().IsDir() && media.IsSeasonDir(c.Name()) {
+			return KindShow
+		}
+	}
+	if hasDisc(n) {
+		return KindMovie
+	}
+	seasons := map[int]bool{}
+	videos, episodes := countVideos(n, seasons)
+	switch {
+	case videos == 0:
+		return KindUnknown
+	case episodes*2 > videos && len(seasons) > 1:
+		return KindShow
+	case episodes*2 > videos && videos > 1:
+		return KindSeason
+	case episodes > 0 && videos == 1:
+		return KindEpisode
+	}
+	return KindMovie
+}
+
+// countVideos counts non-sample videos below n and how many carry an episode
+// marker, adding the season numbers of those markers to seasons.
+func countVideos(n *treeview.Node[treeview.FileInfo], seasons map[int]bool) (videos, episodes int) {
+	for _, c := range n.Children() {
+		if c.Data().IsDir() {
+			if !media.IsSampleDir(c.Name()) {
+				v, e := countVideos(c, seasons)
+				videos, episodes = videos+v, episodes+e
+			}
+			continue
+		}
+		if !media.IsVideo(c.Name()) || media.IsSample(c.Name()) {
+			continue
+		}
+		videos++
+		if media.HasEpisodeMarker(c.Name()) {
+			episodes++
+			if season, _, ok := media.ParseSeasonEpisode(c.Name(), nil); ok {
+				seasons[season] = true
+			}
+		}
+	}
+	return videos, episodes
+}
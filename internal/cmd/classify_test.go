@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/Digital-Shane/treeview"
+)
+
+func TestClassify(t *testing.T) {
+	dir := func(name string, children ...*treeview.Node[treeview.FileInfo]) *treeview.Node[treeview.FileInfo] {
+		n := testNewDirNode(name)
+		n.SetChildren(children)
+		return n
+	}
+	file := testNewFileNode
+	tests := []struct {
+		name string
+		node *treeview.Node[treeview.FileInfo]
+		want Kind
+	}{
+		{"loose episode", file("Show.S01E02.mkv"), KindEpisode},
+		{"loose movie", file("Movie.2020.1080p.x264.mkv"), KindMovie},
+		{"loose sample", file("Movie.2020.sample.mkv"), KindUnknown},
+		{"loose episode subtitle", file("Show.S01E02.en.srt"), KindEpisode},
+		{"loose movie subtitle", file("Movie.2020.en.srt"), KindUnknown},
+		{"not media", file("notes.txt"), KindUnknown},
+		{"show with season folders", dir("Show", dir("Season 1", file("S01E01.mkv")), dir("Season 2")), KindShow},
+		{"season pack", dir("Show.S01", file("Show.S01E01.mkv"), file("Show.S01E02.mkv"), file("Show.S01E03.mkv")), KindSeason},
+		{"flat show", dir("Show", file("Show.S01E01.mkv"), file("Show.S01E02.mkv"), file("Show.S02E01.mkv")), KindShow},
+		{"single episode folder", dir("Show.S01E02.720p", file("Show.S01E02.720p.mkv"), file("Show.S01E02.720p.nfo")), KindEpisode},
+		{"movie with extras", dir("Movie.2020", file("Movie.2020.mkv"), file("Movie.2020.sample.mkv"), dir("Featurettes", file("Behind the scenes.mkv"))), KindMovie},
+		{"empty folder", dir("Empty"), KindUnknown},
+	}
+	for _, tc := range tests {
+		if got := Classify(tc.node); got != tc.want {
+			t.Errorf("Classify(%s) = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
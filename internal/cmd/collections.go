@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/media"
+	"github.com/Digital-Shane/treeview"
+)
+
+// CollectionMap assigns movies to collections. Keys are lower-case movie names,
+// with or without their year ("the matrix (1999)" or "the matrix").
+type CollectionMap map[string]string
+
+// LoadCollectionMap reads a collection mapping file. Each line names a movie and
+// its collection separated by "=":
+//
+//	The Matrix (1999) = The Matrix Collection
+//	The Matrix Reloaded = The Matrix Collection
+//
+// Blank lines and lines starting with # are ignored.
+func LoadCollectionMap(path string) (CollectionMap, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m := CollectionMap{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		movie, collection, ok := strings.Cut(text, "=")
+		movie, collection = strings.TrimSpace(movie), strings.TrimSpace(collection)
+		if !ok || movie == "" || collection == "" {
+			return nil, fmt.Errorf("%s:%d: want \"movie = collection\"", path, line)
+		}
+		m[strings.ToLower(movie)] = collection
+	}
+	return m, scanner.Err()
+}
+
+// lookup returns the collection of a movie named name ("The Matrix (1999)"),
+// trying the name with its year first.
+func (m CollectionMap) lookup(name string) string {
+	low := strings.ToLower(name)
+	if c := m[low]; c != "" {
+		return c
+	}
+	if i := strings.LastIndex(low, " ("); i > 0 && strings.HasSuffix(low, ")") {
+		return m[low[:i]]
+	}
+	return ""
+}
+
+// GroupCollections moves the movies of t that belong to a collection into a
+// virtual collection folder: "The Matrix Collection/The Matrix (1999)/". The
+// collection comes from mapping first, then from the <set> tag of an NFO file in
+// the movie. A movie placed elsewhere (DestDir) takes its collection with it.
+func GroupCollections(t *treeview.Tree[treeview.FileInfo], mapping CollectionMap) {
+	collections := map[string]*treeview.Node[treeview.FileInfo]{} // DestDir + name -> collection node
+	var out []*treeview.Node[treeview.FileInfo]
+	for _, n := range t.Nodes() {
+		mm := core.GetMeta(n)
+		if mm == nil || mm.Type != core.MediaMovie || mm.NewName == "" || mm.MarkedForDeletion {
+			out = append(out, n)
+			continue
+		}
+		name := mapping.lookup(mm.NewName)
+		if name == "" {
+			name = nfoCollection(n)
+		}
+		name = strings.TrimSpace(strings.ReplaceAll(name, string(filepath.Separator), "-"))
+		if name == "" {
+			out = append(out, n)
+			continue
+		}
+		key := filepath.Join(mm.DestDir, name)
+		c, exists := collections[key]
+		if !exists {
+			c = treeview.NewNode(key, name, treeview.FileInfo{FileInfo: &SimpleFileInfo{name: name, isDir: true}, Path: name})
+			cm := core.EnsureMeta(c)
+			cm.Type = core.MediaCollection
+			cm.NewName = name
+			cm.IsVirtual = true
+			cm.NeedsDirectory = true
+			cm.DestDir = mm.DestDir
+			collections[key] = c
+			out = append(out, c)
+		}
+		if !mm.IsVirtual {
+			retargetDestDirs(n, filepath.Join(baseDir(mm.DestDir), name, mm.NewName))
+		}
+		mm.DestDir = ""
+		c.AddChild(n)
+	}
+	t.SetNodes(out)
+}
+
+// baseDir returns the directory virtual folders are created in: dir, or the
+// working directory when dir is empty.
+func baseDir(dir string) string {
+	if dir == "" {
+		return "."
+	}
+	return dir
+}
+
+// retargetDestDirs points files planned to move into movie or one of its folders
+// (Subs flattened beside the video, extras filed into Trailers/) at the movie's
+// new location, since the movie folder is moved into its collection before those
+// files are renamed.
+func retargetDestDirs(movie *treeview.Node[treeview.FileInfo], dest string) {
+	var walk func(n *treeview.Node[treeview.FileInfo])
+	walk = func(n *treeview.Node[treeview.FileInfo]) {
+		for _, c := range n.Children() {
+			cm := core.GetMeta(c)
+			if cm != nil && cm.DestDir != "" {
+				if rel, err := filepath.Rel(movie.Data().Path, cm.DestDir); err == nil && !strings.HasPrefix(rel, "..") {
+					cm.DestDir = filepath.Join(dest, rel)
+				}
+			}
+			walk(c)
+		}
+	}
+	walk(movie)
+}
+
+// nfoCollection returns the collection named by the <set> tag of the first NFO
+// file directly inside movie that has one, or "".
+func nfoCollection(movie *treeview.Node[treeview.FileInfo]) string {
+	for _, c := range movie.Children() {
+		if c.Data().IsDir() || media.CategoryOf(c.Name()) != media.CategoryNFO {
+			continue
+		}
+		if name := readNFOSet(c.Data().Path); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// readNFOSet reads the collection of a Kodi style movie NFO, written either as
+// <set><name>The Matrix Collection</name></set> or as <set>The Matrix Collection</set>.
+// Text after the XML document (such as a scraper URL) is ignored.
+func readNFOSet(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	var nfo struct {
+		Set struct {
+			Name string `xml:"name"`
+			Text string `xml:",chardata"`
+		} `xml:"set"`
+	}
+	if err := xml.NewDecoder(f).Decode(&nfo); err != nil {
+		return ""
+	}
+	if name := strings.TrimSpace(nfo.Set.Name); name != "" {
+		return name
+	}
+	return strings.TrimSpace(nfo.Set.Text)
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+)
+
+func TestLoadCollectionMap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "collections.txt")
+	os.WriteFile(path, []byte("# franchises\nThe Matrix (1999) = The Matrix Collection\n\nThe Matrix Reloaded=The Matrix Collection\n"), 0644)
+	m, err := LoadCollectionMap(path)
+	if err != nil {
+		t.Fatalf("LoadCollectionMap() error = %v", err)
+	}
+	for name, want := range map[string]string{
+		"The Matrix (1999)":          "The Matrix Collection",
+		"the matrix reloaded (2003)": "The Matrix Collection",
+		"The Matrix":                 "",
+		"Alien (1979)":               "",
+	} {
+		if got := m.lookup(name); got != want {
+			t.Errorf("lookup(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	os.WriteFile(path, []byte("The Matrix (1999)\n"), 0644)
+	if _, err := LoadCollectionMap(path); err == nil {
+		t.Error("LoadCollectionMap(line without =) error = nil, want error")
+	}
+}
+
+func TestReadNFOSet(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name, content, want string
+	}{
+		{"nested", "<movie><title>Alien</title><set><name>Alien Collection</name><overview>...</overview></set></movie>", "Alien Collection"},
+		{"plain", "<?xml version=\"1.0\"?>\n<movie><set> Alien Collection </set></movie>\nhttps://www.themoviedb.org/movie/348", "Alien Collection"},
+		{"none", "<movie><title>Alien</title></movie>", ""},
+		{"not xml", "https://www.imdb.com/title/tt0078748/", ""},
+	}
+	for _, tc := range tests {
+		path := filepath.Join(dir, tc.name+".nfo")
+		os.WriteFile(path, []byte(tc.content), 0644)
+		if got := readNFOSet(path); got != tc.want {
+			t.Errorf("readNFOSet(%s) = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestMoviesCommandGroupsCollections(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	for _, f := range []string{
+		"The.Matrix.1999.1080p/The.Matrix.1999.1080p.mkv",
+		"The.Matrix.1999.1080p/Subs/English.srt",
+		"The.Matrix.Reloaded.2003.1080p.mkv",
+		"Alien.1979/Alien.1979.mkv",
+		"Heat.1995.mkv",
+	} {
+		os.MkdirAll(filepath.Dir(f), 0755)
+		os.WriteFile(f, []byte("video"), 0644)
+	}
+	os.WriteFile("Alien.1979/movie.nfo", []byte("<movie><set><name>Alien Collection</name></set></movie>"), 0644)
+
+	cfg := MoviesCommand
+	cfg.Collections = true
+	cfg.CollectionMap = CollectionMap{"the matrix": "The Matrix Collection", "the matrix reloaded (2003)": "The Matrix Collection"}
+	indexed, err := IndexTree(cfg, ".")
+	if err != nil {
+		t.Fatalf("IndexTree() error = %v", err)
+	}
+	tr := BuildPlan(cfg, UnwrapRoot(indexed))
+	collections := 0
+	for _, n := range tr.Nodes() {
+		if mm := core.GetMeta(n); mm != nil && mm.Type == core.MediaCollection {
+			collections++
+		}
+	}
+	if collections != 2 {
+		t.Errorf("BuildPlan() collections = %d, want 2", collections)
+	}
+
+	if rc := NewRenameModel(cfg, tr).RunAll(); rc.ErrorCount() != 0 {
+		t.Errorf("RunAll() errors = %d, want 0", rc.ErrorCount())
+	}
+	for _, want := range []string{
+		"The Matrix Collection/The Matrix (1999)/The Matrix (1999).mkv",
+		"The Matrix Collection/The Matrix (1999)/The Matrix (1999).en.srt",
+		"The Matrix Collection/The Matrix Reloaded (2003)/The Matrix Reloaded (2003).mkv",
+		"Alien Collection/Alien (1979)/Alien (1979).mkv",
+		"Heat (1995)/Heat (1995).mkv",
+	} {
+		if _, err := os.Stat(want); err != nil {
+			t.Errorf("RunAll() did not create %s: %v", want, err)
+		}
+	}
+}
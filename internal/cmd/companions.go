@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Digital-Shane/title-tidy/internal/charset"
+	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/langdetect"
+	"github.com/Digital-Shane/title-tidy/internal/media"
+	"github.com/Digital-Shane/treeview"
+)
+
+// PairVobSubs keeps VobSub subtitles working after a rename. The .sub stream must
+// share the base name of its .idx index, so a .sub beside an annotated .idx with the
+// same original base name follows the new name of the .idx (language code included).
+func PairVobSubs(t *treeview.Tree[treeview.FileInfo]) {
+	for ni := range t.All(context.Background()) {
+		idx := ni.Node
+		ext := media.ExtractExtension(idx.Name())
+		im := core.GetMeta(idx)
+		if !strings.EqualFold(ext, ".idx") || im == nil || im.NewName == "" || im.MarkedForDeletion {
+			continue
+		}
+		stem := strings.TrimSuffix(idx.Name(), ext)
+		newStem := strings.TrimSuffix(im.NewName, media.ExtractExtension(im.NewName))
+		siblings := t.Nodes()
+		if p := idx.Parent(); p != nil {
+			siblings = p.Children()
+		}
+		for _, sub := range siblings {
+			subExt := media.ExtractExtension(sub.Name())
+			if sub.Data().IsDir() || !strings.EqualFold(subExt, ".sub") || strings.TrimSuffix(sub.Name(), subExt) != stem {
+				continue
+			}
+			sm := core.EnsureMeta(sub)
+			sm.Type = im.Type
+			sm.NewName = newStem + subExt
+			sm.DestDir = im.DestDir
+		}
+	}
+}
+
+// maxSubtitleRead bounds how much of a subtitle is read to detect its language.
+const maxSubtitleRead = 256 << 10
+
+// DetectSubtitleLanguages reads text subtitles (SRT, VTT, ASS/SSA) whose new name
+// carries no language and adds the language detected from their contents, so
+// several untagged subtitles of one video no longer collide. The guess is recorded
+// in DetectedLanguage for the TUI to show before anything is renamed.
+func DetectSubtitleLanguages(t *treeview.Tree[treeview.FileInfo]) {
+	for ni := range t.All(context.Background()) {
+		n := ni.Node
+		mm := core.GetMeta(n)
+		if mm == nil || mm.NewName == "" || mm.MarkedForDeletion || !isTextSubtitle(n.Name()) {
+			continue
+		}
+		tags, raw := media.ParseSubtitleTags(mm.NewName)
+		if tags.Language != "" {
+			continue
+		}
+		lang := detectFileLanguage(n.Data().Path)
+		if lang == "" {
+			continue
+		}
+		tags.Language = lang
+		mm.NewName = strings.TrimSuffix(mm.NewName, raw) + tags.Suffix(media.ExtractExtension(mm.NewName))
+		mm.DetectedLanguage = lang
+	}
+}
+
+// isTextSubtitle reports whether filename is a subtitle format stored as text.
+func isTextSubtitle(filename string) bool {
+	switch strings.ToLower(media.ExtractExtension(filename)) {
+	case ".srt", ".vtt", ".ass", ".ssa":
+		return media.IsSubtitle(filename)
+	}
+	return false
+}
+
+// detectFileLanguage returns the language of the subtitle at path, or "" when it
+// cannot be read or no language stands out.
+func detectFileLanguage(path string) string {
+	data, err := readHead(path)
+	if err != nil {
+		return ""
+	}
+	text := charset.Decode(data, charset.Detect(data))
+	lang, _ := langdetect.Detect(langdetect.SubtitleText([]byte(text)))
+	return lang
+}
+
+// MarkEncodingConversions plans the conversion to UTF-8 of every subtitle written
+// in a legacy encoding such as Windows-1252 or Windows-1251. Binary formats
+// (VobSub, PGS) are recognized as such and left alone.
+func MarkEncodingConversions(t *treeview.Tree[treeview.FileInfo]) {
+	for ni := range t.All(context.Background()) {
+		n := ni.Node
+		if n.Data().IsDir() || !media.IsSubtitle(n.Name()) {
+			continue
+		}
+		if mm := core.GetMeta(n); mm != nil && mm.MarkedForDeletion {
+			continue
+		}
+		data, err := readHead(n.Data().Path)
+		if err != nil {
+			continue
+		}
+		if enc := charset.Detect(data); enc == charset.Windows1252 || enc == charset.Windows1251 {
+			core.EnsureMeta(n).ConvertFrom = enc.String()
+		}
+	}
+}
+
+// readHead returns up to maxSubtitleRead bytes from the start of the file at path.
+// A UTF-8 character cut in two by the limit is dropped so the head of a UTF-8
+// file still validates as UTF-8.
+func readHead(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxSubtitleRead))
+	if err != nil || len(data) < maxSubtitleRead {
+		return data, err
+	}
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				data = data[:i]
+			}
+			break
+		}
+	}
+	return data, nil
+}
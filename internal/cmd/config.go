@@ -2,13 +2,18 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/ignore"
 	"github.com/Digital-Shane/title-tidy/internal/media"
+	"github.com/Digital-Shane/title-tidy/internal/trash"
 	"github.com/Digital-Shane/title-tidy/internal/tui"
 
 	"github.com/Digital-Shane/treeview"
@@ -16,32 +21,70 @@ import (
 )
 
 // CommandConfig describes how to construct and annotate a tree for a given subcommand. Fields:
-//   - maxDepth: depth budget for filesystem enumeration.
+//   - maxDepth: depth of the canonical layout (and the enumeration budget unless deepScan is set).
+//   - deepScan: index up to Depth levels and flatten files found below the canonical layout.
 //   - includeDirs: whether directory entries pass the filter.
 //   - preprocess: optional in-memory node transformation prior to tree
 //     construction (e.g. injecting virtual directories around loose movie files).
 //   - annotate: optional pass to attach MediaMeta (type + proposed name).
+//   - place: optional pass after annotate that relocates nodes (sets DestDir), e.g.
+//     into a library for ingest.
 //   - movieMode: toggles movie-oriented statistics & wording in the TUI.
+//   - autoMode: entries pick their own mode (see AutoCommand) and can be
+//     reclassified from the TUI.
 //   - InstantMode: apply renames immediately without interactive preview.
 //   - DeleteNFO: mark NFO files for deletion during rename.
 //   - DeleteImages: mark image files for deletion during rename.
+//   - Upgrade: how renames onto an existing file are resolved.
+//   - UpgradeBySize: break quality ties between copies using file size.
+//   - HardDelete: permanently delete instead of moving files to the trash.
+//   - Quarantine: directory that receives deleted files instead of the trash.
+//   - DeleteRules: additional rules selecting files and folders to delete.
+//   - DeleteSamples: mark detected release samples for deletion.
+//   - SampleRatio: videos of a movie folder smaller than this fraction of its largest video are samples (0 disables).
+//   - KeepEmptyDirs: skip removing directories emptied by the run.
+//   - PruneEmptyDirs: also remove directories that were already empty before the run.
+//   - Depth: directory levels indexed by deep scanning modes; 0 uses DefaultScanDepth.
+//   - ConvertUTF8: convert subtitles in legacy encodings to UTF-8, keeping a backup.
+//   - Extras: where trailers, featurettes and other extras are placed.
+//   - Collections: move movies that belong to a collection into a collection folder.
+//   - CollectionMap: user assignments of movies to collections, ahead of NFO <set> tags.
 type CommandConfig struct {
-	maxDepth     int
-	includeDirs  bool
-	preprocess   func([]*treeview.Node[treeview.FileInfo]) []*treeview.Node[treeview.FileInfo]
-	annotate     func(*treeview.Tree[treeview.FileInfo])
-	movieMode    bool
-	InstantMode  bool
-	DeleteNFO    bool
-	DeleteImages bool
+	maxDepth       int
+	deepScan       bool
+	includeDirs    bool
+	preprocess     func([]*treeview.Node[treeview.FileInfo]) []*treeview.Node[treeview.FileInfo]
+	annotate       func(*treeview.Tree[treeview.FileInfo])
+	place          func(*treeview.Tree[treeview.FileInfo])
+	movieMode      bool
+	autoMode       bool
+	InstantMode    bool
+	DeleteNFO      bool
+	DeleteImages   bool
+	Upgrade        UpgradePolicy
+	UpgradeBySize  bool
+	HardDelete     bool
+	Quarantine     string
+	DeleteRules    []DeleteRule
+	DeleteSamples  bool
+	SampleRatio    float64
+	KeepEmptyDirs  bool
+	PruneEmptyDirs bool
+	Depth          int
+	ConvertUTF8    bool
+	Extras         ExtrasPolicy
+	Collections    bool
+	CollectionMap  CollectionMap
 }
 
 func RunCommand(cfg CommandConfig) error {
 	// 1. Run indexing (filesystem scan + progress UI) once.
+	ignored := ignore.New(".")
 	idxModel := tui.NewIndexProgressModel(".", tui.IndexConfig{
-		MaxDepth:    cfg.maxDepth,
+		MaxDepth:    cfg.indexDepth(),
 		IncludeDirs: cfg.includeDirs,
-		Filter:      CreateMediaFilter(cfg.includeDirs),
+		Filter:      CreateMediaFilter(cfg.includeDirs, cfg.DeleteRules...),
+		Ignore:      ignored,
 	})
 	finalModel, err := tea.NewProgram(idxModel, tea.WithAltScreen()).Run()
 	if err != nil {
@@ -57,58 +100,173 @@ func RunCommand(cfg CommandConfig) error {
 		return fmt.Errorf("indexing produced no tree")
 	}
 
-	// 2. Prepare nodes
-	nodes := UnwrapRoot(t)
+	// 2-3. Prepare nodes, rebuild the application tree and annotate it.
+	t = BuildPlan(cfg, UnwrapRoot(t))
+	model := NewRenameModel(cfg, t)
+	model.Ignore = ignored
+
+	// If instant mode, perform renames immediately
+	if cfg.InstantMode {
+		if result := model.RunAll(); result.ErrorCount() > 0 {
+			return fmt.Errorf("%d errors occurred during renaming", result.ErrorCount())
+		}
+		return nil
+	}
+	// 4. Launch rename TUI
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	_, err = p.Run()
+	return err
+}
+
+// withMode returns c with the tree shape and naming passes of mode while keeping
+// the options (deletion, upgrade, cleanup...) set on c.
+func (c CommandConfig) withMode(mode CommandConfig) CommandConfig {
+	c.maxDepth = mode.maxDepth
+	c.deepScan = mode.deepScan
+	c.includeDirs = mode.includeDirs
+	c.preprocess = mode.preprocess
+	c.annotate = mode.annotate
+	c.place = mode.place
+	c.movieMode = mode.movieMode
+	c.autoMode = mode.autoMode
+	return c
+}
+
+// IndexTree scans path without the progress UI, applying the same depth, filter
+// and ignore file settings RunCommand uses. Headless commands (watch, ingest) start here.
+func IndexTree(cfg CommandConfig, path string) (*treeview.Tree[treeview.FileInfo], error) {
+	root := path
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		root = filepath.Dir(path)
+	}
+	return treeview.NewTreeFromFileSystem(context.Background(), path, false,
+		treeview.WithMaxDepth[treeview.FileInfo](cfg.indexDepth()),
+		treeview.WithTraversalCap[treeview.FileInfo](2000000),
+		treeview.WithFilterFunc(indexFilter(cfg, root)),
+	)
+}
+
+// IndexEntries indexes the named top-level entries of the working directory as
+// IndexTree(cfg, ".") would, without scanning the rest of it. Entries excluded
+// by the filter or an ignore file, and entries no longer on disk, are skipped.
+func IndexEntries(cfg CommandConfig, names []string) ([]*treeview.Node[treeview.FileInfo], error) {
+	filter := indexFilter(cfg, ".")
+	var nodes []*treeview.Node[treeview.FileInfo]
+	for _, name := range names {
+		path, err := filepath.Abs(name)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Lstat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !filter(treeview.FileInfo{FileInfo: info, Path: path}) {
+			continue
+		}
+		// The entry is one level below the working directory, so one level of the
+		// depth budget is already spent.
+		depth := cfg.indexDepth() - 1
+		if !info.IsDir() || depth <= 0 {
+			nodes = append(nodes, treeview.NewFileSystemNode(path, info))
+			continue
+		}
+		t, err := treeview.NewTreeFromFileSystem(context.Background(), path, false,
+			treeview.WithMaxDepth[treeview.FileInfo](depth),
+			treeview.WithTraversalCap[treeview.FileInfo](2000000),
+			treeview.WithFilterFunc(filter),
+		)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, t.Nodes()...)
+	}
+	return nodes, nil
+}
+
+// indexFilter combines the media filter of cfg with the ignore files found from root.
+func indexFilter(cfg CommandConfig, root string) func(treeview.FileInfo) bool {
+	ignored := ignore.New(root)
+	filter := CreateMediaFilter(cfg.includeDirs, cfg.DeleteRules...)
+	return func(info treeview.FileInfo) bool {
+		return !ignored.Match(info.Path, info.IsDir()) && filter(info)
+	}
+}
+
+// BuildPlan turns indexed top-level nodes into the annotated tree shown by the
+// TUI: preprocess, annotate, seal disc structures, detect subtitle languages, pair VobSub files, then
+// mark samples, deletions, merges, upgrades and encoding conversions.
+func BuildPlan(cfg CommandConfig, nodes []*treeview.Node[treeview.FileInfo]) *treeview.Tree[treeview.FileInfo] {
 	if cfg.preprocess != nil {
 		nodes = cfg.preprocess(nodes)
 	}
-
-	// 3. Rebuild application tree with provider and expansion.
-	t = treeview.NewTree(nodes,
+	t := treeview.NewTree(nodes,
 		treeview.WithExpandAll[treeview.FileInfo](),
 		treeview.WithProvider(tui.CreateRenameProvider()),
 	)
 	if cfg.annotate != nil {
 		cfg.annotate(t)
 	}
+	MarkDiscs(t)
+	MarkExtras(t, cfg.Extras)
+	DetectSubtitleLanguages(t)
+	PairVobSubs(t)
+	if cfg.place != nil {
+		cfg.place(t)
+	}
+	if cfg.Collections {
+		GroupCollections(t, cfg.CollectionMap)
+	}
+	MarkSamples(t, cfg.SampleRatio, cfg.DeleteSamples)
 
-	// Mark files for deletion based on flags
-	MarkFilesForDeletion(t, cfg.DeleteNFO, cfg.DeleteImages)
+	// Mark files for deletion based on flags and rules
+	MarkForDeletion(t, deletionRules(cfg.DeleteNFO, cfg.DeleteImages, cfg.DeleteRules))
+	MarkVersions(t)
+	MarkDirectoryMerges(t)
+	MarkUpgrades(t, cfg.Upgrade, cfg.UpgradeBySize)
+	if cfg.ConvertUTF8 {
+		MarkEncodingConversions(t)
+	}
+	return t
+}
 
-	// Create model
+// NewRenameModel creates the rename model for t configured from cfg.
+func NewRenameModel(cfg CommandConfig, t *treeview.Tree[treeview.FileInfo]) *tui.RenameModel {
 	model := tui.NewRenameModel(t)
 	model.IsMovieMode = cfg.movieMode
 	model.DeleteNFO = cfg.DeleteNFO
 	model.DeleteImages = cfg.DeleteImages
-
-	// If instant mode, perform renames immediately
-	if cfg.InstantMode {
-		cmd := model.PerformRenames()
-		if cmd != nil {
-			msg := cmd()
-			if result, ok := msg.(tui.RenameCompleteMsg); ok && result.ErrorCount() > 0 {
-				return fmt.Errorf("%d errors occurred during renaming", result.ErrorCount())
-			}
+	model.Bin = trash.Bin{Quarantine: cfg.Quarantine, Hard: cfg.HardDelete}
+	model.CleanEmptyDirs = !cfg.KeepEmptyDirs
+	model.PruneEmptyDirs = cfg.PruneEmptyDirs
+	if cfg.autoMode {
+		model.Reclassify = func(n *treeview.Node[treeview.FileInfo]) ([]*treeview.Node[treeview.FileInfo], error) {
+			return ReclassifyEntry(cfg, n)
 		}
-		return nil
 	}
-	// 4. Launch rename TUI
-	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
-	_, err = p.Run()
-	return err
+	return model
 }
 
 // CreateMediaFilter returns a filter function that excludes common junk files
 // and optionally filters for specific file types based on the includeDirectories parameter.
-func CreateMediaFilter(includeDirectories bool) func(info treeview.FileInfo) bool {
+// Entries matching any of rules are always indexed so they can be shown as deletions.
+func CreateMediaFilter(includeDirectories bool, rules ...DeleteRule) func(info treeview.FileInfo) bool {
 	return func(info treeview.FileInfo) bool {
 		if info.Name() == ".DS_Store" || strings.HasPrefix(info.Name(), "._") {
 			return false
 		}
-		if includeDirectories {
-			return info.IsDir() || media.IsSubtitle(info.Name()) || media.IsVideo(info.Name()) || media.IsNFO(info.Name()) || media.IsImage(info.Name())
+		for _, r := range rules {
+			if r.Match(info) && (includeDirectories || !info.IsDir()) {
+				return true
+			}
+		}
+		if includeDirectories && info.IsDir() {
+			return true
 		}
-		return media.IsSubtitle(info.Name()) || media.IsVideo(info.Name()) || media.IsNFO(info.Name()) || media.IsImage(info.Name())
+		return media.CategoryOf(info.Name()) != media.CategoryNone
 	}
 }
 
@@ -154,29 +312,56 @@ func (m *SimpleFileInfo) Sys() any           { return nil }
 
 // MarkFilesForDeletion traverses the tree and marks NFO and/or image files for deletion
 func MarkFilesForDeletion(t *treeview.Tree[treeview.FileInfo], deleteNFO, deleteImages bool) {
-	if !deleteNFO && !deleteImages {
-		return
+	MarkForDeletion(t, deletionRules(deleteNFO, deleteImages, nil))
+}
+
+// deletionRules combines the --no-nfo / --no-img shortcuts with user rules.
+func deletionRules(deleteNFO, deleteImages bool, extra []DeleteRule) []DeleteRule {
+	var rules []DeleteRule
+	if deleteNFO {
+		rules = append(rules, DeleteRule{Kind: "nfo"})
+	}
+	if deleteImages {
+		rules = append(rules, DeleteRule{Kind: "image"})
+	}
+	return append(rules, extra...)
+}
+
+// plannedPath returns where an annotated node will end up. Nodes inside a virtual
+// directory are created below their parent's planned path.
+func plannedPath(n *treeview.Node[treeview.FileInfo]) string {
+	mm := core.GetMeta(n)
+	if parent := n.Parent(); parent != nil {
+		if pm := core.GetMeta(parent); pm != nil && pm.IsVirtual {
+			return filepath.Join(plannedPath(parent), mm.NewName)
+		}
 	}
+	return mm.Destination(n.Data().Path)
+}
 
+// MarkDirectoryMerges flags directories whose destination already exists, either
+// on disk or because an earlier directory in the same run claims that name, so
+// the rename executor merges their children instead of failing.
+func MarkDirectoryMerges(t *treeview.Tree[treeview.FileInfo]) {
+	claimed := map[string]bool{}
 	for ni := range t.All(context.Background()) {
-		if ni.Node.Data().IsDir() {
+		n := ni.Node
+		mm := core.GetMeta(n)
+		if mm == nil || mm.NewName == "" || mm.MarkedForDeletion || !n.Data().IsDir() {
 			continue
 		}
-
-		filename := ni.Node.Name()
-		shouldDelete := false
-
-		if deleteNFO && media.IsNFO(filename) {
-			shouldDelete = true
+		dest := plannedPath(n)
+		key, _ := filepath.Abs(dest) // virtual directories are planned relative to the working directory
+		if claimed[key] {
+			mm.MergeIntoExisting = true
+			continue
 		}
-
-		if deleteImages && media.IsImage(filename) {
-			shouldDelete = true
+		claimed[key] = true
+		if !mm.IsVirtual && !mm.NeedsRename(n.Name()) {
+			continue
 		}
-
-		if shouldDelete {
-			meta := core.EnsureMeta(ni.Node)
-			meta.MarkedForDeletion = true
+		if info, err := os.Stat(dest); err == nil && info.IsDir() {
+			mm.MergeIntoExisting = true
 		}
 	}
 }
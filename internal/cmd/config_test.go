@@ -3,10 +3,12 @@ package cmd
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/ignore"
 	"github.com/Digital-Shane/treeview"
 	"github.com/google/go-cmp/cmp"
 )
@@ -240,3 +242,60 @@ func assertBool(t *testing.T, got bool, want bool, desc string) {
 		t.Errorf("%s = %v, want %v", desc, got, want)
 	}
 }
+
+func TestMarkDirectoryMerges(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	if err := os.Mkdir("Show (2024)", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	onDisk := testNewDirNode("Show.2024.S02")
+	core.EnsureMeta(onDisk).NewName = "Show (2024)"
+	first := testNewDirNode("Other.Show.S01")
+	core.EnsureMeta(first).NewName = "Other Show"
+	second := testNewDirNode("Other.Show.S02")
+	core.EnsureMeta(second).NewName = "Other Show"
+	fresh := testNewDirNode("New.Show")
+	core.EnsureMeta(fresh).NewName = "New Show"
+	file := testNewFileNode("Show (2024).mkv")
+	core.EnsureMeta(file).NewName = "Show (2024)"
+
+	MarkDirectoryMerges(testNewTree(onDisk, first, second, fresh, file))
+
+	for _, tc := range []struct {
+		node *treeview.Node[treeview.FileInfo]
+		want bool
+	}{{onDisk, true}, {first, false}, {second, true}, {fresh, false}, {file, false}} {
+		if got := core.GetMeta(tc.node).MergeIntoExisting; got != tc.want {
+			t.Errorf("MarkDirectoryMerges(%s) = %v, want %v", tc.node.Name(), got, tc.want)
+		}
+	}
+}
+
+func TestIndexTreeHonorsIgnoreFiles(t *testing.T) {
+	tmp := t.TempDir()
+	for _, f := range []string{"Movie.2020/movie.mkv", "@eaDir/Movie.2020/thumb.mkv", "Other.2021/other.mkv", "Other.2021/extra.mkv"} {
+		os.MkdirAll(filepath.Join(tmp, filepath.Dir(f)), 0755)
+		os.WriteFile(filepath.Join(tmp, f), []byte("video"), 0644)
+	}
+	os.WriteFile(filepath.Join(tmp, ignore.FileName), []byte("@eaDir/\n"), 0644)
+	os.WriteFile(filepath.Join(tmp, "Other.2021", ignore.FileName), []byte("extra.mkv\n"), 0644)
+
+	tr, err := IndexTree(MoviesCommand, tmp)
+	if err != nil {
+		t.Fatalf("IndexTree() error = %v", err)
+	}
+	for _, name := range []string{"@eaDir", "thumb.mkv", "extra.mkv", ignore.FileName} {
+		if findNodeByName(tr, name) != nil {
+			t.Errorf("IndexTree() indexed ignored entry %s", name)
+		}
+	}
+	for _, name := range []string{"movie.mkv", "other.mkv"} {
+		if findNodeByName(tr, name) == nil {
+			t.Errorf("IndexTree() skipped %s", name)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/media"
+	"github.com/Digital-Shane/treeview"
+)
+
+// MarkDiscs keeps DVD and Blu-ray structures intact. Folders such as VIDEO_TS,
+// BDMV and CERTIFICATE drop their indexed contents, so no later pass renames,
+// flattens or deletes the files inside, and are shown as a single disc entry.
+// Only the folder holding the structure is renamed.
+func MarkDiscs(t *treeview.Tree[treeview.FileInfo]) {
+	var discs []*treeview.Node[treeview.FileInfo]
+	for ni := range t.All(context.Background()) {
+		if ni.Node.Data().IsDir() && media.IsDiscDir(ni.Node.Name()) && !underDisc(ni.Node) {
+			discs = append(discs, ni.Node)
+		}
+	}
+	for _, d := range discs {
+		d.SetChildren(nil)
+		*core.EnsureMeta(d) = core.MediaMeta{Type: core.MediaDisc}
+	}
+}
+
+// underDisc reports whether n sits inside a disc structure folder.
+func underDisc(n *treeview.Node[treeview.FileInfo]) bool {
+	for p := n.Parent(); p != nil; p = p.Parent() {
+		if media.IsDiscDir(p.Name()) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDisc reports whether dir directly holds a DVD or Blu-ray structure.
+func hasDisc(dir *treeview.Node[treeview.FileInfo]) bool {
+	for _, c := range dir.Children() {
+		if c.Data().IsDir() && media.IsDiscDir(c.Name()) {
+			return true
+		}
+	}
+	return false
+}
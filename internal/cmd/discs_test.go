@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+)
+
+func TestMoviesCommandKeepsDiscStructures(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	for _, f := range []string{
+		"Dvd.Movie.2020/VIDEO_TS/VTS_01_1.VOB",
+		"Dvd.Movie.2020/VIDEO_TS/VTS_01_2.VOB",
+		"Blu.Ray.Movie.2019/BDMV/STREAM/00001.m2ts",
+		"Blu.Ray.Movie.2019/BDMV/META/DL/cover.jpg",
+		"Blu.Ray.Movie.2019/CERTIFICATE/id.bdmv",
+		"Image.Movie.2018.iso",
+	} {
+		os.MkdirAll(filepath.Dir(f), 0755)
+		os.WriteFile(f, []byte("data"), 0644)
+	}
+
+	cfg := MoviesCommand
+	cfg.DeleteImages = true
+	indexed, err := IndexTree(cfg, ".")
+	if err != nil {
+		t.Fatalf("IndexTree() error = %v", err)
+	}
+	tr := BuildPlan(cfg, UnwrapRoot(indexed))
+	for _, name := range []string{"VIDEO_TS", "BDMV", "CERTIFICATE"} {
+		n := findNodeByName(tr, name)
+		if mm := core.GetMeta(n); mm == nil || mm.Type != core.MediaDisc || len(n.Children()) != 0 {
+			t.Errorf("BuildPlan() %s = %#v with %d children, want a sealed disc", name, mm, len(n.Children()))
+		}
+	}
+
+	if rc := NewRenameModel(cfg, tr).RunAll(); rc.ErrorCount() != 0 {
+		t.Errorf("RunAll() errors = %d, want 0", rc.ErrorCount())
+	}
+	for _, want := range []string{
+		"Dvd Movie (2020)/VIDEO_TS/VTS_01_1.VOB",
+		"Dvd Movie (2020)/VIDEO_TS/VTS_01_2.VOB",
+		"Blu Ray Movie (2019)/BDMV/STREAM/00001.m2ts",
+		"Blu Ray Movie (2019)/BDMV/META/DL/cover.jpg",
+		"Blu Ray Movie (2019)/CERTIFICATE/id.bdmv",
+		"Image Movie (2018)/Image Movie (2018).iso",
+	} {
+		if _, err := os.Stat(want); err != nil {
+			t.Errorf("RunAll() did not keep %s: %v", want, err)
+		}
+	}
+}
+
+func TestClassifyDisc(t *testing.T) {
+	dir := testNewDirNode("Blu.Ray.Movie.2019")
+	dir.AddChild(testNewDirNode("BDMV"))
+	if got := Classify(dir); got != KindMovie {
+		t.Errorf("Classify(disc folder) = %q, want %q", got, KindMovie)
+	}
+}
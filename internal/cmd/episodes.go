@@ -2,21 +2,28 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
 	"github.com/Digital-Shane/title-tidy/internal/core"
 	"github.com/Digital-Shane/title-tidy/internal/media"
 	"github.com/Digital-Shane/treeview"
 )
 
 // EpisodesCommand processes a flat directory of episode files (no parent season folder).
-// Each top-level media file is classified as an episode and renamed solely based on
-// information present in its own filename (no contextual season inference).
+// Episodes whose filename names the show are grouped into virtual Show/Season folders
+// by EpisodePreprocess; the rest are renamed in place solely based on information
+// present in their own filename (no contextual season inference).
 var EpisodesCommand = CommandConfig{
 	maxDepth:    1,
 	includeDirs: false,
+	preprocess:  EpisodePreprocess,
 	annotate: func(t *treeview.Tree[treeview.FileInfo]) {
 		for ni := range t.All(context.Background()) {
-			// Only operate on leaf nodes (files) at depth 0; directories are excluded by includeDirs=false.
-			if ni.Node.Data().IsDir() {
+			// Only operate on files; directories are excluded by includeDirs=false and
+			// episodes grouped during preprocess are already annotated.
+			if ni.Node.Data().IsDir() || core.GetMeta(ni.Node) != nil {
 				continue
 			}
 			m := core.EnsureMeta(ni.Node)
@@ -25,3 +32,65 @@ var EpisodesCommand = CommandConfig{
 		}
 	},
 }
+
+// EpisodePreprocess groups loose episode files (videos and companions with an explicit
+// SxxExx marker and a show title before it) into nested virtual directories, so
+// "Show.A.S01E01.mkv" becomes "Show A/Season 01/S01E01.mkv" when materialized.
+// Files without a recognizable show title are left in place, and nothing is grouped
+// when the files already sit in a season folder (episodes run from Show/Season 03).
+func EpisodePreprocess(nodes []*treeview.Node[treeview.FileInfo]) []*treeview.Node[treeview.FileInfo] {
+	if len(nodes) > 0 {
+		if dir, err := filepath.Abs(filepath.Dir(nodes[0].Data().Path)); err == nil && media.IsSeasonDir(filepath.Base(dir)) {
+			return nodes
+		}
+	}
+	shows := map[string]*treeview.Node[treeview.FileInfo]{}
+	seasons := map[string]*treeview.Node[treeview.FileInfo]{}
+	var out []*treeview.Node[treeview.FileInfo]
+
+	for _, n := range nodes {
+		name := n.Name()
+		if n.Data().IsDir() || !(media.IsVideo(name) || media.IsCompanion(name)) || media.IsSample(name) || !media.HasEpisodeMarker(name) {
+			out = append(out, n)
+			continue
+		}
+		show := media.ShowNameFromRelease(name)
+		season, _, ok := media.ParseSeasonEpisode(name, nil)
+		if show == "" || !ok {
+			out = append(out, n)
+			continue
+		}
+		// Releases of one show differ in case; the first one names the folder.
+		showDir, exists := shows[strings.ToLower(show)]
+		if !exists {
+			showDir = newVirtualDir(show, show, core.MediaShow)
+			shows[strings.ToLower(show)] = showDir
+			out = append(out, showDir)
+		}
+		seasonName := fmt.Sprintf("Season %02d", season)
+		key := filepath.Join(showDir.Name(), seasonName)
+		seasonDir, exists := seasons[key]
+		if !exists {
+			seasonDir = newVirtualDir(key, seasonName, core.MediaSeason)
+			seasons[key] = seasonDir
+			showDir.AddChild(seasonDir)
+		}
+		seasonDir.AddChild(n)
+		em := core.EnsureMeta(n)
+		em.Type = core.MediaEpisode
+		em.NewName = media.FormatEpisodeName(name, n)
+	}
+	return out
+}
+
+// newVirtualDir creates a directory node that will be created during rename.
+// path is relative to the working directory and name is the final folder name.
+func newVirtualDir(path, name string, kind core.MediaType) *treeview.Node[treeview.FileInfo] {
+	vd := treeview.NewNode(path, name, treeview.FileInfo{FileInfo: &SimpleFileInfo{name: name, isDir: true}, Path: path})
+	vm := core.EnsureMeta(vd)
+	vm.Type = kind
+	vm.NewName = name
+	vm.IsVirtual = true
+	vm.NeedsDirectory = true
+	return vd
+}
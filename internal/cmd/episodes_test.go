@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/Digital-Shane/title-tidy/internal/core"
@@ -26,3 +28,84 @@ func TestEpisodesCommandAnnotate(t *testing.T) {
 		t.Errorf("EpisodesCommand annotate directory meta unexpectedly set")
 	}
 }
+
+func TestEpisodePreprocess(t *testing.T) {
+	e1 := testNewFileNode("Show.A.S01E01.mkv")
+	e1sub := testNewFileNode("Show.A.S01E01.en.srt")
+	e2 := testNewFileNode("Show.A.S02E05.mkv")
+	lower := testNewFileNode("show.a.s01e02.mkv")
+	other := testNewFileNode("Show.B.S01E03.mkv")
+	bare := testNewFileNode("S01E02.mkv")
+	notes := testNewFileNode("notes.txt")
+
+	out := EpisodePreprocess([]*treeview.Node[treeview.FileInfo]{e1, e1sub, e2, lower, other, bare, notes})
+	if notes.Parent() != nil {
+		t.Errorf("EpisodePreprocess() grouped a non-media file")
+	}
+	tr := testNewTree(out...)
+	EpisodesCommand.annotate(tr)
+
+	if len(out) != 4 {
+		t.Fatalf("EpisodePreprocess() returned %d nodes, want 4 (2 shows, bare episode, notes)", len(out))
+	}
+	show := out[0]
+	if sm := core.GetMeta(show); sm == nil || !sm.IsVirtual || sm.NewName != "Show A" || sm.Type != core.MediaShow {
+		t.Errorf("EpisodePreprocess() show meta = %#v, want virtual show %q", sm, "Show A")
+	}
+	if len(show.Children()) != 2 {
+		t.Fatalf("EpisodePreprocess() Show A seasons = %d, want 2", len(show.Children()))
+	}
+	for _, tc := range []struct {
+		node     *treeview.Node[treeview.FileInfo]
+		season   string
+		wantName string
+	}{
+		{e1, "Season 01", "S01E01.mkv"},
+		{e1sub, "Season 01", "S01E01.en.srt"},
+		{e2, "Season 02", "S02E05.mkv"},
+		{lower, "Season 01", "S01E02.mkv"},
+		{other, "Season 01", "S01E03.mkv"},
+	} {
+		parent := tc.node.Parent()
+		if pm := core.GetMeta(parent); parent == nil || pm.NewName != tc.season || !pm.IsVirtual {
+			t.Errorf("EpisodePreprocess(%s) season = %v, want virtual %q", tc.node.Name(), pm, tc.season)
+		}
+		if got := core.GetMeta(tc.node).NewName; got != tc.wantName {
+			t.Errorf("EpisodePreprocess(%s) NewName = %q, want %q", tc.node.Name(), got, tc.wantName)
+		}
+	}
+	if lower.Parent() != e1.Parent() {
+		t.Errorf("EpisodePreprocess() split %s from %s by case", lower.Name(), e1.Name())
+	}
+	if bare.Parent() != nil || core.GetMeta(bare).NewName != "S01E02.mkv" {
+		t.Errorf("EpisodePreprocess() grouped an episode without a show name")
+	}
+}
+
+func TestEpisodesCommandInSeasonFolder(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	season := filepath.Join(tmp, "Show Name", "Season 03")
+	os.MkdirAll(season, 0755)
+	os.Chdir(season)
+	for _, f := range []string{"Show.Name.S03E01.mkv", "show.name.s03e02.mkv", "3x03.mkv", "Show.Name.S03E07.en-US.srt"} {
+		os.WriteFile(f, []byte("video"), 0644)
+	}
+
+	indexed, err := IndexTree(EpisodesCommand, ".")
+	if err != nil {
+		t.Fatalf("IndexTree() error = %v", err)
+	}
+	if rc := NewRenameModel(EpisodesCommand, BuildPlan(EpisodesCommand, UnwrapRoot(indexed))).RunAll(); rc.ErrorCount() != 0 {
+		t.Errorf("RunAll() errors = %d, want 0", rc.ErrorCount())
+	}
+	for _, want := range []string{"S03E01.mkv", "S03E02.mkv", "S03E03.mkv", "S03E07.en-US.srt"} {
+		if _, err := os.Stat(want); err != nil {
+			t.Errorf("RunAll() did not rename in place to %s: %v", want, err)
+		}
+	}
+	if _, err := os.Stat("Show Name"); !os.IsNotExist(err) {
+		t.Errorf("RunAll() grouped episodes into a show folder inside the season folder")
+	}
+}
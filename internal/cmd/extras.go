@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/media"
+	"github.com/Digital-Shane/treeview"
+)
+
+// ExtrasPolicy selects where detected extras (trailers, featurettes...) go.
+type ExtrasPolicy int
+
+const (
+	ExtrasFolder ExtrasPolicy = iota // Filed into Trailers/, Featurettes/... inside the movie or show
+	ExtrasSuffix                     // Kept beside the main feature with a -trailer, -featurette... suffix
+	ExtrasOff                        // Not detected; renamed like any other file
+)
+
+// ParseExtrasPolicy resolves the --extras values folder, suffix and off.
+func ParseExtrasPolicy(s string) (ExtrasPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "folder", "folders":
+		return ExtrasFolder, nil
+	case "suffix":
+		return ExtrasSuffix, nil
+	case "off", "none":
+		return ExtrasOff, nil
+	}
+	return ExtrasFolder, fmt.Errorf("unknown extras policy %q (want folder, suffix or off)", s)
+}
+
+// MarkExtras keeps trailers, featurettes, behind the scenes and deleted scenes
+// from being renamed after the main feature. Inside a movie, show or season an
+// extra is a video named with an extras keyword that is smaller than the largest
+// video beside it (so "Trailer Park Boys" itself still counts as the movie and
+// episodes are never taken for extras), or any video in an extras folder. Extras
+// keep their own name and are filed into the matching extras folder, or suffixed
+// ("Behind the Scenes-behindthescenes.mkv") with ExtrasSuffix. Loose movies
+// wrapped in a virtual folder always use the suffix.
+func MarkExtras(t *treeview.Tree[treeview.FileInfo], policy ExtrasPolicy) {
+	if policy == ExtrasOff {
+		return
+	}
+	for ni := range t.All(context.Background()) {
+		n := ni.Node
+		mm := core.GetMeta(n)
+		if mm == nil || !n.Data().IsDir() {
+			continue
+		}
+		switch mm.Type {
+		case core.MediaMovie, core.MediaShow, core.MediaSeason:
+			markExtrasIn(n, mm, policy)
+		}
+	}
+}
+
+// markExtrasIn detects the extras among the direct children of container.
+func markExtrasIn(container *treeview.Node[treeview.FileInfo], cm *core.MediaMeta, policy ExtrasPolicy) {
+	if cm.IsVirtual {
+		policy = ExtrasSuffix // the virtual folder only places its direct children
+	}
+	var largest int64
+	for _, c := range container.Children() {
+		if !c.Data().IsDir() && media.IsVideo(c.Name()) {
+			largest = max(largest, c.Data().Size())
+		}
+	}
+	for _, c := range container.Children() {
+		if c.Data().IsDir() {
+			if kind, ok := media.ExtrasDirKind(c.Name()); ok {
+				markExtrasDir(container, c, kind, policy)
+			}
+			continue
+		}
+		kind := media.ExtraKindOf(c.Name())
+		if kind == media.ExtraNone || !media.IsVideo(c.Name()) || media.HasEpisodeMarker(c.Name()) || c.Data().Size() >= largest {
+			continue
+		}
+		placeExtra(container, c, kind, policy)
+	}
+}
+
+// markExtrasDir handles an extras folder inside container. A folder of a single
+// kind is kept (under its canonical name) when extras are filed into folders;
+// otherwise, and for generic Extras/ folders, its videos are placed one by one
+// and the folder is left to the cleanup.
+func markExtrasDir(container, dir *treeview.Node[treeview.FileInfo], kind media.ExtraKind, policy ExtrasPolicy) {
+	if kind != media.ExtraNone && policy == ExtrasFolder {
+		*core.EnsureMeta(dir) = core.MediaMeta{Type: core.MediaExtra, NewName: kind.Folder()}
+		for _, f := range dir.Children() {
+			if !f.Data().IsDir() && media.IsVideo(f.Name()) {
+				*core.EnsureMeta(f) = core.MediaMeta{Type: core.MediaExtra, NewName: f.Name()}
+			}
+		}
+		return
+	}
+	markFlattened(dir)
+	for _, f := range dir.Children() {
+		if f.Data().IsDir() || !media.IsVideo(f.Name()) {
+			continue
+		}
+		k := kind
+		if k == media.ExtraNone {
+			if k = media.ExtraKindOf(f.Name()); k == media.ExtraNone {
+				k = media.ExtraFeaturette
+			}
+		}
+		placeExtra(container, f, k, policy)
+	}
+}
+
+// placeExtra annotates the extra n of container: moved into its extras folder,
+// or named with the extras suffix beside the main feature.
+func placeExtra(container, n *treeview.Node[treeview.FileInfo], kind media.ExtraKind, policy ExtrasPolicy) {
+	m := core.EnsureMeta(n)
+	*m = core.MediaMeta{Type: core.MediaExtra, NewName: n.Name()}
+	if policy == ExtrasFolder {
+		m.DestDir = filepath.Join(container.Data().Path, kind.Folder())
+		return
+	}
+	ext := media.ExtractExtension(n.Name())
+	base := strings.TrimSuffix(n.Name(), ext)
+	if !strings.HasSuffix(strings.ToLower(base), kind.Suffix()) {
+		m.NewName = base + kind.Suffix() + ext
+	}
+	if n.Parent() != container {
+		m.DestDir = container.Data().Path
+	}
+}
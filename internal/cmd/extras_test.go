@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+)
+
+// writeExtrasFixture creates the files of a release layout; videos get a size
+// so the main feature is the largest video of its folder.
+func writeExtrasFixture(files map[string]int) {
+	for f, size := range files {
+		os.MkdirAll(filepath.Dir(f), 0755)
+		os.WriteFile(f, []byte(strings.Repeat("v", size)), 0644)
+	}
+}
+
+func TestParseExtrasPolicy(t *testing.T) {
+	for in, want := range map[string]ExtrasPolicy{"": ExtrasFolder, "folder": ExtrasFolder, "Suffix": ExtrasSuffix, "off": ExtrasOff} {
+		if got, err := ParseExtrasPolicy(in); err != nil || got != want {
+			t.Errorf("ParseExtrasPolicy(%q) = (%d, %v), want %d", in, got, err, want)
+		}
+	}
+	if _, err := ParseExtrasPolicy("keep"); err == nil {
+		t.Error("ParseExtrasPolicy(\"keep\") error = nil, want error")
+	}
+}
+
+func TestMoviesCommandFilesExtras(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	writeExtrasFixture(map[string]int{
+		"Movie.2020.1080p/Movie.2020.1080p.mkv":             100,
+		"Movie.2020.1080p/Movie-trailer.mkv":                5,
+		"Movie.2020.1080p/Behind the Scenes.mkv":            8,
+		"Movie.2020.1080p/featurettes/Visual Effects.mkv":   3,
+		"Movie.2020.1080p/Extras/Deleted Scene 1.mkv":       2,
+		"Movie.2020.1080p/Extras/Cast Interviews.mkv":       2,
+		"Trailer.Park.Boys.2006/Trailer.Park.Boys.2006.mkv": 50,
+	})
+
+	indexed, err := IndexTree(MoviesCommand, ".")
+	if err != nil {
+		t.Fatalf("IndexTree() error = %v", err)
+	}
+	tr := BuildPlan(MoviesCommand, UnwrapRoot(indexed))
+	if mm := core.GetMeta(findNodeByName(tr, "Movie-trailer.mkv")); mm == nil || mm.Type != core.MediaExtra {
+		t.Errorf("BuildPlan() trailer meta = %#v, want an extra", mm)
+	}
+	if rc := NewRenameModel(MoviesCommand, tr).RunAll(); rc.ErrorCount() != 0 {
+		t.Errorf("RunAll() errors = %d, want 0", rc.ErrorCount())
+	}
+	for _, want := range []string{
+		"Movie (2020)/Movie (2020).mkv",
+		"Movie (2020)/Trailers/Movie-trailer.mkv",
+		"Movie (2020)/Behind The Scenes/Behind the Scenes.mkv",
+		"Movie (2020)/Featurettes/Visual Effects.mkv",
+		"Movie (2020)/Deleted Scenes/Deleted Scene 1.mkv",
+		"Movie (2020)/Featurettes/Cast Interviews.mkv",
+		"Trailer Park Boys (2006)/Trailer Park Boys (2006).mkv",
+	} {
+		if _, err := os.Stat(want); err != nil {
+			t.Errorf("RunAll() did not create %s: %v", want, err)
+		}
+	}
+	if _, err := os.Stat("Movie (2020)/Extras"); !os.IsNotExist(err) {
+		t.Errorf("RunAll() left the emptied Extras folder")
+	}
+}
+
+func TestMoviesCommandSuffixesExtras(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	writeExtrasFixture(map[string]int{
+		"Movie.2020/Movie.2020.mkv":            100,
+		"Movie.2020/Making Of.mkv":             5,
+		"Movie.2020/Trailers/Teaser.mkv":       2,
+		"Movie.2020/Movie.2020-deleted.mkv":    2,
+		"Movie.2020/Deleted Scenes/Ending.mkv": 2,
+	})
+
+	cfg := MoviesCommand
+	cfg.Extras = ExtrasSuffix
+	indexed, err := IndexTree(cfg, ".")
+	if err != nil {
+		t.Fatalf("IndexTree() error = %v", err)
+	}
+	if rc := NewRenameModel(cfg, BuildPlan(cfg, UnwrapRoot(indexed))).RunAll(); rc.ErrorCount() != 0 {
+		t.Errorf("RunAll() errors = %d, want 0", rc.ErrorCount())
+	}
+	for _, want := range []string{
+		"Movie (2020)/Movie (2020).mkv",
+		"Movie (2020)/Making Of-featurette.mkv",
+		"Movie (2020)/Teaser-trailer.mkv",
+		"Movie (2020)/Movie.2020-deleted.mkv",
+		"Movie (2020)/Ending-deleted.mkv",
+	} {
+		if _, err := os.Stat(want); err != nil {
+			t.Errorf("RunAll() did not create %s: %v", want, err)
+		}
+	}
+}
+
+func TestShowsCommandKeepsSeasonExtras(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	writeExtrasFixture(map[string]int{
+		"Show/Season 1/Show.S01E01.mkv":            100,
+		"Show/Season 1/Featurettes/Pilot Look.mkv": 5,
+		"Show/Season 1/Show.S01.Trailer.mkv":       5,
+	})
+
+	indexed, err := IndexTree(ShowsCommand, ".")
+	if err != nil {
+		t.Fatalf("IndexTree() error = %v", err)
+	}
+	if rc := NewRenameModel(ShowsCommand, BuildPlan(ShowsCommand, UnwrapRoot(indexed))).RunAll(); rc.ErrorCount() != 0 {
+		t.Errorf("RunAll() errors = %d, want 0", rc.ErrorCount())
+	}
+	for _, want := range []string{
+		"Show/Season 01/S01E01.mkv",
+		"Show/Season 01/Featurettes/Pilot Look.mkv",
+		"Show/Season 01/Trailers/Show.S01.Trailer.mkv",
+	} {
+		if _, err := os.Stat(want); err != nil {
+			t.Errorf("RunAll() did not create %s: %v", want, err)
+		}
+	}
+}
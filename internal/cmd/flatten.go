@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/media"
+	"github.com/Digital-Shane/treeview"
+)
+
+// DefaultScanDepth is how many directory levels the shows and seasons commands
+// index below the working directory unless --depth says otherwise.
+const DefaultScanDepth = 6
+
+// indexDepth returns the depth budget used to enumerate the filesystem. Deep
+// scanning modes look past their canonical layout up to Depth (or
+// DefaultScanDepth) so nested files can be flattened.
+func (c CommandConfig) indexDepth() int {
+	if !c.deepScan {
+		return c.maxDepth
+	}
+	depth := c.Depth
+	if depth <= 0 {
+		depth = DefaultScanDepth
+	}
+	return max(depth, c.maxDepth)
+}
+
+// FlattenShow reworks a show annotated by depth so nested layouts end up as
+// Show/Season NN/episode. Folders below a season (Disc 1, extras wrappers...) are
+// flattened into that season, and season folders wrapped in a non-season folder
+// (Show/Complete Series/Season 1) are moved up beside the other seasons, and
+// episodes lying loose in the show are grouped into virtual season folders.
+func FlattenShow(show *treeview.Node[treeview.FileInfo]) {
+	for _, c := range show.Children() {
+		if !c.Data().IsDir() {
+			continue
+		}
+		if media.FormatSeasonName(c.Name()) != "" {
+			flattenSubdirs(c)
+			continue
+		}
+		seasons := nestedSeasons(c)
+		if len(seasons) == 0 {
+			continue
+		}
+		markFlattened(c)
+		for _, s := range seasons {
+			sm := core.EnsureMeta(s)
+			sm.Type = core.MediaSeason
+			sm.NewName = media.FormatSeasonName(s.Name())
+			sm.DestDir = show.Data().Path
+			for _, e := range s.Children() {
+				if !e.Data().IsDir() {
+					em := core.EnsureMeta(e)
+					em.Type = core.MediaEpisode
+					em.NewName = media.FormatEpisodeName(e.Name(), e)
+				}
+			}
+			flattenSubdirs(s)
+		}
+	}
+	groupLooseEpisodes(show)
+}
+
+// groupLooseEpisodes moves the episode files kept directly in show (Show/S02E01.mkv)
+// into virtual Season NN folders created inside it, like EpisodePreprocess does for
+// a flat download directory.
+func groupLooseEpisodes(show *treeview.Node[treeview.FileInfo]) {
+	seasons := map[int]*treeview.Node[treeview.FileInfo]{}
+	var children []*treeview.Node[treeview.FileInfo]
+	for _, c := range show.Children() {
+		name := c.Name()
+		season, _, ok := media.ParseSeasonEpisode(name, nil)
+		if c.Data().IsDir() || !(media.IsVideo(name) || media.IsCompanion(name)) || media.IsSample(name) || !ok {
+			children = append(children, c)
+			continue
+		}
+		seasonDir, exists := seasons[season]
+		if !exists {
+			seasonName := fmt.Sprintf("Season %02d", season)
+			seasonDir = newVirtualDir(filepath.Join(show.Data().Path, seasonName), seasonName, core.MediaSeason)
+			core.GetMeta(seasonDir).DestDir = show.Data().Path
+			seasons[season] = seasonDir
+			children = append(children, seasonDir)
+		}
+		seasonDir.AddChild(c)
+		em := core.EnsureMeta(c)
+		em.Type = core.MediaEpisode
+		em.NewName = media.FormatEpisodeName(name, c)
+	}
+	if len(seasons) > 0 {
+		show.SetChildren(children)
+	}
+}
+
+// flattenSubdirs moves every file below the sub-folders of season directly into
+// season and marks those sub-folders for cleanup. Subs folders are handled last so
+// their subtitles can follow the episodes they belong to.
+func flattenSubdirs(season *treeview.Node[treeview.FileInfo]) {
+	var subs []*treeview.Node[treeview.FileInfo]
+	for _, c := range season.Children() {
+		switch {
+		case !c.Data().IsDir():
+		case media.IsSubsDir(c.Name()):
+			subs = append(subs, c)
+		default:
+			flattenInto(season, c)
+		}
+	}
+	for _, s := range subs {
+		flattenSubs(season, s, videosBelow(season))
+	}
+}
+
+// flattenInto annotates the videos and companions of dir, a folder somewhere
+// below season, that are named after an episode to be moved into season. Other
+// files (a disc NFO, cover art) stay where they are, in their folder under its
+// own name, and only folders left empty become cleanup candidates. It reports whether everything in dir moves.
+func flattenInto(season, dir *treeview.Node[treeview.FileInfo]) bool {
+	emptied := true
+	for _, c := range dir.Children() {
+		if c.Data().IsDir() {
+			if !flattenInto(season, c) {
+				emptied = false
+			}
+			continue
+		}
+		name := ""
+		if media.IsVideo(c.Name()) || media.IsCompanion(c.Name()) {
+			name = media.FormatEpisodeNameInSeason(c.Name(), season.Name())
+		}
+		if name == "" {
+			emptied = false
+			continue
+		}
+		m := core.EnsureMeta(c)
+		m.Type = core.MediaEpisode
+		m.NewName = name
+		m.DestDir = season.Data().Path
+	}
+	if emptied {
+		markFlattened(dir)
+	} else if extra := dir.Data().Extra; extra != nil {
+		delete(extra, "meta") // kept under its own name for what stays in it
+	}
+	return emptied
+}
+
+// markFlattened flags an intermediate folder whose contents move elsewhere.
+func markFlattened(dir *treeview.Node[treeview.FileInfo]) {
+	m := core.EnsureMeta(dir)
+	m.Type = core.MediaFlattened
+	m.NewName = ""
+}
+
+// nestedSeasons returns the season folders anywhere below dir, without
+// descending into the seasons themselves.
+func nestedSeasons(dir *treeview.Node[treeview.FileInfo]) []*treeview.Node[treeview.FileInfo] {
+	var seasons []*treeview.Node[treeview.FileInfo]
+	for _, c := range dir.Children() {
+		switch {
+		case !c.Data().IsDir():
+		case media.FormatSeasonName(c.Name()) != "":
+			seasons = append(seasons, c)
+		default:
+			seasons = append(seasons, nestedSeasons(c)...)
+		}
+	}
+	return seasons
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+)
+
+func TestIndexDepth(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   CommandConfig
+		depth int
+		want  int
+	}{
+		{"movies keep their layout depth", MoviesCommand, 0, 3},
+		{"shows default", ShowsCommand, 0, DefaultScanDepth},
+		{"shows custom", ShowsCommand, 4, 4},
+		{"never shallower than the layout", ShowsCommand, 1, 3},
+	}
+	for _, tc := range tests {
+		tc.cfg.Depth = tc.depth
+		if got := tc.cfg.indexDepth(); got != tc.want {
+			t.Errorf("indexDepth(%s) = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestShowsCommandFlattensDeepFiles(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	for _, f := range []string{
+		"Show.Name/Season 1/Disc 1/Show.Name.S01E01.mkv",
+		"Show.Name/Season 1/Disc 2/Extra/Show.Name.S01E02.mkv",
+		"Show.Name/Complete Series/Season 2/Show.Name.S02E01.mkv",
+	} {
+		os.MkdirAll(filepath.Dir(f), 0755)
+		os.WriteFile(f, []byte("video"), 0644)
+	}
+
+	indexed, err := IndexTree(ShowsCommand, ".")
+	if err != nil {
+		t.Fatalf("IndexTree() error = %v", err)
+	}
+	tr := BuildPlan(ShowsCommand, UnwrapRoot(indexed))
+	for _, name := range []string{"Disc 1", "Disc 2", "Extra", "Complete Series"} {
+		if mm := core.GetMeta(findNodeByName(tr, name)); mm == nil || mm.Type != core.MediaFlattened {
+			t.Errorf("BuildPlan() %s meta = %#v, want flattened", name, mm)
+		}
+	}
+
+	rc := NewRenameModel(ShowsCommand, tr).RunAll()
+	if rc.ErrorCount() != 0 {
+		t.Errorf("RunAll() errors = %d, want 0", rc.ErrorCount())
+	}
+	for _, want := range []string{
+		"Show Name/Season 01/S01E01.mkv",
+		"Show Name/Season 01/S01E02.mkv",
+		"Show Name/Season 02/S02E01.mkv",
+	} {
+		if _, err := os.Stat(want); err != nil {
+			t.Errorf("RunAll() did not create %s: %v", want, err)
+		}
+	}
+	for _, gone := range []string{"Show Name/Season 01/Disc 1", "Show Name/Season 01/Disc 2", "Show Name/Complete Series"} {
+		if _, err := os.Stat(gone); !os.IsNotExist(err) {
+			t.Errorf("RunAll() left intermediate directory %s", gone)
+		}
+	}
+}
+
+func TestShowsCommandKeepsStrayFilesOfFlattenedFolders(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	for _, f := range []string{
+		"Show.Name/Season 1/Disc 1/Show.Name.S01E01.mkv",
+		"Show.Name/Season 1/Disc 1/Show.Name.S01E01.en.srt",
+		"Show.Name/Season 1/Disc 1/disc.nfo",
+		"Show.Name/Season 1/Disc 2/Show.Name.S01E02.mkv",
+		"Show.Name/Season 1/Disc 2/Art/cover.jpg",
+	} {
+		os.MkdirAll(filepath.Dir(f), 0755)
+		os.WriteFile(f, []byte("data"), 0644)
+	}
+
+	indexed, err := IndexTree(ShowsCommand, ".")
+	if err != nil {
+		t.Fatalf("IndexTree() error = %v", err)
+	}
+	tr := BuildPlan(ShowsCommand, UnwrapRoot(indexed))
+	for _, name := range []string{"Disc 1", "Disc 2", "Art"} {
+		if mm := core.GetMeta(findNodeByName(tr, name)); mm != nil && mm.Type == core.MediaFlattened {
+			t.Errorf("BuildPlan() flattened %s although files stay in it", name)
+		}
+	}
+
+	if rc := NewRenameModel(ShowsCommand, tr).RunAll(); rc.ErrorCount() != 0 {
+		t.Errorf("RunAll() errors = %d, want 0", rc.ErrorCount())
+	}
+	for _, want := range []string{
+		"Show Name/Season 01/S01E01.mkv",
+		"Show Name/Season 01/S01E01.en.srt",
+		"Show Name/Season 01/S01E02.mkv",
+		"Show Name/Season 01/Disc 1/disc.nfo",
+		"Show Name/Season 01/Disc 2/Art/cover.jpg",
+	} {
+		if _, err := os.Stat(want); err != nil {
+			t.Errorf("RunAll() did not leave %s: %v", want, err)
+		}
+	}
+}
+
+func TestSeasonsCommandFlattensSubfolders(t *testing.T) {
+	season := testNewDirNode("Show.S01")
+	disc := testNewDirNode("CD1")
+	ep := testNewFileNode("Show.S01E01.mkv")
+	disc.AddChild(ep)
+	season.AddChild(disc)
+
+	SeasonsCommand.annotate(testNewTree(season))
+
+	if mm := core.GetMeta(disc); mm.Type != core.MediaFlattened || mm.NewName != "" {
+		t.Errorf("SeasonsCommand annotate subfolder = %#v, want flattened", mm)
+	}
+	if mm := core.GetMeta(ep); mm.NewName != "S01E01.mkv" || mm.DestDir != "Show.S01" {
+		t.Errorf("SeasonsCommand annotate nested episode = %q in %q, want S01E01.mkv in Show.S01", mm.NewName, mm.DestDir)
+	}
+}
+
+func TestShowsCommandFlattensDiscsIntoTheirSeason(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	for _, f := range []string{
+		"Show.Name/Season 2/Disc 1/03.mkv",
+		"Show.Name/Season 2/Disc 2/05.mkv",
+		"Show.Name/Season 2/CD 3/Episode 07.en.srt",
+	} {
+		os.MkdirAll(filepath.Dir(f), 0755)
+		os.WriteFile(f, []byte("data"), 0644)
+	}
+
+	indexed, err := IndexTree(ShowsCommand, ".")
+	if err != nil {
+		t.Fatalf("IndexTree() error = %v", err)
+	}
+	if rc := NewRenameModel(ShowsCommand, BuildPlan(ShowsCommand, UnwrapRoot(indexed))).RunAll(); rc.ErrorCount() != 0 {
+		t.Errorf("RunAll() errors = %d, want 0", rc.ErrorCount())
+	}
+	for _, want := range []string{
+		"Show Name/Season 02/S02E03.mkv",
+		"Show Name/Season 02/S02E05.mkv",
+		"Show Name/Season 02/S02E07.en.srt",
+	} {
+		if _, err := os.Stat(want); err != nil {
+			t.Errorf("RunAll() did not create %s: %v", want, err)
+		}
+	}
+}
+
+func TestShowsCommandGroupsLooseEpisodesIntoSeasons(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	for _, f := range []string{
+		"Show.Name/Show.Name.S01E01.mkv",
+		"Show.Name/Show.Name.S01E01.en.srt",
+		"Show.Name/Show.Name.S02E01.mkv",
+		"Show.Name/Season 2/Show.Name.S02E02.mkv",
+		"Show.Name/tvshow.nfo",
+	} {
+		os.MkdirAll(filepath.Dir(f), 0755)
+		os.WriteFile(f, []byte("data"), 0644)
+	}
+
+	indexed, err := IndexTree(ShowsCommand, ".")
+	if err != nil {
+		t.Fatalf("IndexTree() error = %v", err)
+	}
+	if rc := NewRenameModel(ShowsCommand, BuildPlan(ShowsCommand, UnwrapRoot(indexed))).RunAll(); rc.ErrorCount() != 0 {
+		t.Errorf("RunAll() errors = %d, want 0", rc.ErrorCount())
+	}
+	for _, want := range []string{
+		"Show Name/Season 01/S01E01.mkv",
+		"Show Name/Season 01/S01E01.en.srt",
+		"Show Name/Season 02/S02E01.mkv",
+		"Show Name/Season 02/S02E02.mkv",
+		"Show Name/tvshow.nfo",
+	} {
+		if _, err := os.Stat(want); err != nil {
+			t.Errorf("RunAll() did not create %s: %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/media"
+	"github.com/Digital-Shane/title-tidy/internal/trash"
+	"github.com/Digital-Shane/title-tidy/internal/tui"
+	"github.com/Digital-Shane/treeview"
+)
+
+// Ingest exit codes, chosen for download client and *arr post-processing hooks.
+const (
+	IngestOK           = 0 // everything was renamed and placed
+	IngestFailed       = 1 // bad arguments, missing library, or unreadable path
+	IngestUnrecognized = 2 // no media found; nothing was changed
+	IngestPartial      = 3 // some operations failed; see IngestResult.Errors
+)
+
+// IngestTransfer selects how a download gets into the library.
+type IngestTransfer int
+
+const (
+	TransferMove IngestTransfer = iota // The download itself is renamed into the library
+	TransferCopy                       // The download is copied and left in place (keeps torrents seeding)
+	TransferLink                       // Files are hard linked into the library, copied when linking fails
+)
+
+// IngestConfig holds the ingest-specific settings layered on top of a CommandConfig.
+//
+// Fields:
+//   - Path: completed download (file or directory) to process.
+//   - Movies: library root receiving "Movie (Year)/" folders.
+//   - Shows: library root receiving "Show/Season NN/" folders.
+//   - Transfer: move (default), copy or hard link the download.
+type IngestConfig struct {
+	Path     string
+	Movies   string
+	Shows    string
+	Transfer IngestTransfer
+}
+
+// IngestResult reports what ingest did; it is printed as JSON for hooks.
+type IngestResult struct {
+	Source      string       `json:"source"`
+	Kind        Kind         `json:"kind"`
+	Destination string       `json:"destination,omitempty"`
+	Files       []IngestMove `json:"files,omitempty"`
+	Deleted     []string     `json:"deleted,omitempty"`
+	Errors      []string     `json:"errors,omitempty"`
+}
+
+// IngestMove records a single file renamed into the library.
+type IngestMove struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ExitCode maps the result onto the ingest exit codes.
+func (r IngestResult) ExitCode() int {
+	switch {
+	case r.Kind == KindUnknown:
+		return IngestUnrecognized
+	case len(r.Errors) > 0:
+		return IngestPartial
+	}
+	return IngestOK
+}
+
+// RunIngest classifies ic.Path as a movie, show, season pack or episode and
+// renames it into the matching library without prompting. cfg supplies the
+// shared options (deletion rules, upgrade policy, ...); samples are always
+// dropped rather than carried into the library. A single file brings its
+// companions (Episode.en.srt) along. An error means nothing was attempted. With
+// TransferCopy or TransferLink the download is first staged in a hidden folder
+// of the library and the staged copy is renamed instead, so the download stays
+// untouched; deletions then only drop staged files.
+func RunIngest(cfg CommandConfig, ic IngestConfig) (IngestResult, error) {
+	src, err := filepath.Abs(ic.Path)
+	if err != nil {
+		return IngestResult{}, err
+	}
+	result := IngestResult{Source: src}
+
+	probe, err := IndexTree(cfg.withMode(ShowsCommand), src)
+	if err != nil {
+		return result, err
+	}
+	if len(probe.Nodes()) == 0 {
+		return result, nil
+	}
+	result.Kind = Classify(probe.Nodes()[0])
+	mode, ok := result.Kind.Config()
+	if !ok {
+		return result, nil
+	}
+	library := ic.Shows
+	if result.Kind == KindMovie {
+		library = ic.Movies
+	}
+	if library == "" {
+		return result, fmt.Errorf("no library configured for %s", result.Kind)
+	}
+	if library, err = filepath.Abs(library); err != nil {
+		return result, err
+	}
+
+	entries := []string{src}
+	if !probe.Nodes()[0].Data().IsDir() {
+		entries = append(entries, companionsOf(src)...)
+	}
+	dir := filepath.Dir(src) // folder holding the entries being ingested
+	staged := ic.Transfer != TransferMove
+	if staged {
+		staging, err := stageDownload(entries, library, ic.Transfer == TransferLink)
+		if err != nil {
+			return result, err
+		}
+		defer os.RemoveAll(staging)
+		dir = staging
+		cfg.HardDelete = true
+	}
+	cfg.DeleteSamples = true
+
+	mode.place = func(t *treeview.Tree[treeview.FileInfo]) {
+		placeInLibrary(t, result.Kind, library, src)
+	}
+	c := cfg.withMode(mode)
+	var nodes []*treeview.Node[treeview.FileInfo]
+	for _, e := range entries {
+		indexed, err := IndexTree(c, filepath.Join(dir, filepath.Base(e)))
+		if err != nil {
+			return result, err
+		}
+		nodes = append(nodes, indexed.Nodes()...)
+	}
+	t := BuildPlan(c, nodes)
+
+	origins := map[*treeview.Node[treeview.FileInfo]]string{}
+	for ni := range t.All(context.Background()) {
+		origins[ni.Node] = ni.Node.Data().Path
+		if rel, err := filepath.Rel(dir, ni.Node.Data().Path); staged && err == nil {
+			origins[ni.Node] = filepath.Join(filepath.Dir(src), rel)
+		}
+	}
+	NewRenameModel(c, t).RunAll()
+
+	for ni := range t.All(context.Background()) {
+		n := ni.Node
+		mm := core.GetMeta(n)
+		if mm == nil {
+			continue
+		}
+		switch {
+		case mm.RenameStatus == core.RenameStatusError:
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", origins[n], mm.RenameError))
+		case mm.RenameStatus != core.RenameStatusSuccess:
+		case mm.MarkedForDeletion:
+			if !staged { // staged copies are dropped, the download is kept
+				result.Deleted = append(result.Deleted, origins[n])
+			}
+		case !n.Data().IsDir():
+			result.Files = append(result.Files, IngestMove{From: origins[n], To: absPath(tui.CurrentPath(n))})
+		}
+		if result.Destination == "" && mm.DestDir != "" {
+			if n.Data().IsDir() {
+				result.Destination = absPath(tui.CurrentPath(n))
+			} else {
+				result.Destination = mm.DestDir
+			}
+		}
+	}
+	return result, nil
+}
+
+// companionsOf returns the companion files beside the video file path that are
+// named after it: Episode.en.srt, Episode.commentary.mka for Episode.mkv.
+func companionsOf(path string) []string {
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return nil
+	}
+	name := filepath.Base(path)
+	stem := strings.TrimSuffix(name, media.ExtractExtension(name))
+	var companions []string
+	for _, e := range entries {
+		if e.IsDir() || e.Name() == name || !media.IsCompanion(e.Name()) {
+			continue
+		}
+		for _, suffix := range media.CompanionSuffixes(e.Name()) {
+			if e.Name()[:len(e.Name())-len(suffix)] == stem {
+				companions = append(companions, filepath.Join(filepath.Dir(path), e.Name()))
+				break
+			}
+		}
+	}
+	return companions
+}
+
+// stageDownload copies entries, or hard links their files, into a new hidden
+// folder of library and returns that folder. Staging inside the library keeps
+// the later renames on one filesystem.
+func stageDownload(entries []string, library string, link bool) (string, error) {
+	if err := os.MkdirAll(library, 0755); err != nil {
+		return "", err
+	}
+	staging, err := os.MkdirTemp(library, ".title-tidy-ingest-")
+	if err != nil {
+		return "", err
+	}
+	for _, e := range entries {
+		dst := filepath.Join(staging, filepath.Base(e))
+		if link {
+			err = linkTree(e, dst)
+		} else {
+			err = trash.Copy(e, dst)
+		}
+		if err != nil {
+			os.RemoveAll(staging)
+			return "", err
+		}
+	}
+	return staging, nil
+}
+
+// linkTree recreates the directories of src at dst and hard links its files,
+// copying those that cannot be linked (another filesystem).
+func linkTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if os.Link(path, target) == nil {
+			return nil
+		}
+		return trash.Copy(path, target)
+	})
+}
+
+// placeInLibrary points the annotated top-level entries of t at their library
+// folder. Movies and shows land directly under library; a season pack goes below
+// its show folder and single episodes below their show and season folders (or, when
+// already grouped into a virtual show folder, under library like a show).
+func placeInLibrary(t *treeview.Tree[treeview.FileInfo], kind Kind, library, src string) {
+	for _, n := range t.Nodes() {
+		mm := core.GetMeta(n)
+		switch kind {
+		case KindMovie, KindShow:
+			if mm != nil {
+				mm.DestDir = library
+			}
+		case KindSeason:
+			if mm == nil {
+				continue
+			}
+			if mm.NewName == "" {
+				mm.NewName = seasonFromEpisodes(n)
+			}
+			mm.DestDir = filepath.Join(library, showNameFor(n.Name(), src))
+		case KindEpisode:
+			if mm != nil && mm.IsVirtual { // show folder built by EpisodePreprocess
+				mm.DestDir = library
+				continue
+			}
+			episodes := []*treeview.Node[treeview.FileInfo]{n}
+			if n.Data().IsDir() {
+				episodes = n.Children()
+			}
+			for _, e := range episodes {
+				em := core.GetMeta(e)
+				if em == nil || em.NewName == "" {
+					continue
+				}
+				season, _, _ := media.ParseSeasonEpisode(e.Name(), e)
+				em.DestDir = filepath.Join(library, showNameFor(e.Name(), src), fmt.Sprintf("Season %02d", season))
+			}
+		}
+	}
+}
+
+// showNameFor derives the show folder for an entry from its own release name,
+// then the downloaded path, then the folder containing the download.
+func showNameFor(name, src string) string {
+	for _, candidate := range []string{name, filepath.Base(src)} {
+		if show := media.ShowNameFromRelease(candidate); show != "" {
+			return show
+		}
+	}
+	return media.FormatShowName(filepath.Base(filepath.Dir(src)))
+}
+
+// seasonFromEpisodes names a season folder after the first episode inside it,
+// for season packs whose own name carries no season number.
+func seasonFromEpisodes(n *treeview.Node[treeview.FileInfo]) string {
+	for _, c := range n.Children() {
+		if season, _, ok := media.ParseSeasonEpisode(c.Name(), c); ok && media.HasEpisodeMarker(c.Name()) {
+			return fmt.Sprintf("Season %02d", season)
+		}
+	}
+	return ""
+}
+
+// absPath resolves p against the working directory, returning p unchanged on error.
+func absPath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return p
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunIngest(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []string // relative to the download directory
+		path     string   // ingested entry, relative to the download directory
+		wantKind Kind
+		wantDest string // relative to the library root
+		want     []string
+		absent   []string // relative to the library root
+	}{
+		{
+			name:     "loose movie",
+			files:    []string{"Movie.2020.1080p.mkv"},
+			path:     "Movie.2020.1080p.mkv",
+			wantKind: KindMovie,
+			wantDest: "movies/Movie (2020)",
+			want:     []string{"movies/Movie (2020)/Movie (2020).mkv"},
+		},
+		{
+			name:     "movie folder",
+			files:    []string{"Movie.2020.1080p/movie.mkv", "Movie.2020.1080p/movie.en.srt"},
+			path:     "Movie.2020.1080p",
+			wantKind: KindMovie,
+			wantDest: "movies/Movie (2020)",
+			want:     []string{"movies/Movie (2020)/Movie (2020).mkv", "movies/Movie (2020)/Movie (2020).en.srt"},
+		},
+		{
+			name:     "season pack",
+			files:    []string{"Show.Name.S02.1080p/Show.Name.S02E01.mkv", "Show.Name.S02.1080p/Show.Name.S02E02.mkv"},
+			path:     "Show.Name.S02.1080p",
+			wantKind: KindSeason,
+			wantDest: "shows/Show Name/Season 02",
+			want:     []string{"shows/Show Name/Season 02/S02E01.mkv", "shows/Show Name/Season 02/S02E02.mkv"},
+		},
+		{
+			name:     "season pack with sample",
+			files:    []string{"Show.Name.S02.1080p/Show.Name.S02E01.mkv", "Show.Name.S02.1080p/Show.Name.S02E02.mkv", "Show.Name.S02.1080p/Sample/sample.mkv"},
+			path:     "Show.Name.S02.1080p",
+			wantKind: KindSeason,
+			wantDest: "shows/Show Name/Season 02",
+			want:     []string{"shows/Show Name/Season 02/S02E01.mkv", "shows/Show Name/Season 02/S02E02.mkv"},
+			absent:   []string{"shows/Show Name/Season 02/Sample"},
+		},
+		{
+			name:     "loose episode",
+			files:    []string{"Show.Name.S01E04.720p.mkv"},
+			path:     "Show.Name.S01E04.720p.mkv",
+			wantKind: KindEpisode,
+			wantDest: "shows/Show Name",
+			want:     []string{"shows/Show Name/Season 01/S01E04.mkv"},
+		},
+		{
+			name:     "loose episode with subtitle",
+			files:    []string{"Show.Name.S01E05.720p.mkv", "Show.Name.S01E05.720p.en.srt"},
+			path:     "Show.Name.S01E05.720p.mkv",
+			wantKind: KindEpisode,
+			wantDest: "shows/Show Name",
+			want:     []string{"shows/Show Name/Season 01/S01E05.mkv", "shows/Show Name/Season 01/S01E05.en.srt"},
+		},
+		{
+			name:     "episode folder",
+			files:    []string{"Show.Name.S01E03.720p/Show.Name.S01E03.720p.mkv", "Show.Name.S01E03.720p/Show.Name.S01E03.720p.en.srt"},
+			path:     "Show.Name.S01E03.720p",
+			wantKind: KindEpisode,
+			wantDest: "shows/Show Name/Season 01",
+			want:     []string{"shows/Show Name/Season 01/S01E03.mkv", "shows/Show Name/Season 01/S01E03.en.srt"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tmp := t.TempDir()
+			t.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data"))
+			downloads := filepath.Join(tmp, "downloads")
+			for _, f := range tc.files {
+				path := filepath.Join(downloads, f)
+				os.MkdirAll(filepath.Dir(path), 0755)
+				os.WriteFile(path, []byte("data"), 0644)
+			}
+
+			result, err := RunIngest(CommandConfig{}, IngestConfig{
+				Path:   filepath.Join(downloads, tc.path),
+				Movies: filepath.Join(tmp, "movies"),
+				Shows:  filepath.Join(tmp, "shows"),
+			})
+			if err != nil {
+				t.Fatalf("RunIngest() error = %v", err)
+			}
+			if result.Kind != tc.wantKind || result.ExitCode() != IngestOK {
+				t.Errorf("RunIngest() kind = %q exit = %d (errors %v), want %q exit 0", result.Kind, result.ExitCode(), result.Errors, tc.wantKind)
+			}
+			if want := filepath.Join(tmp, tc.wantDest); result.Destination != want {
+				t.Errorf("RunIngest() destination = %q, want %q", result.Destination, want)
+			}
+			for _, f := range tc.want {
+				if _, err := os.Stat(filepath.Join(tmp, f)); err != nil {
+					t.Errorf("RunIngest() did not create %s: %v", f, err)
+				}
+			}
+			for _, f := range tc.absent {
+				if _, err := os.Stat(filepath.Join(tmp, f)); !os.IsNotExist(err) {
+					t.Errorf("RunIngest() carried %s into the library", f)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(downloads, tc.path)); !os.IsNotExist(err) {
+				t.Errorf("RunIngest() left %s in the download directory", tc.path)
+			}
+		})
+	}
+}
+
+func TestRunIngestUnrecognized(t *testing.T) {
+	tmp := t.TempDir()
+	os.WriteFile(filepath.Join(tmp, "readme.txt"), []byte("text"), 0644)
+
+	result, err := RunIngest(CommandConfig{}, IngestConfig{Path: filepath.Join(tmp, "readme.txt"), Movies: tmp, Shows: tmp})
+	if err != nil {
+		t.Fatalf("RunIngest() error = %v", err)
+	}
+	if result.ExitCode() != IngestUnrecognized {
+		t.Errorf("RunIngest(readme.txt) exit = %d, want %d", result.ExitCode(), IngestUnrecognized)
+	}
+}
+
+func TestRunIngestMissingLibrary(t *testing.T) {
+	tmp := t.TempDir()
+	os.WriteFile(filepath.Join(tmp, "Movie.2020.mkv"), []byte("video"), 0644)
+
+	if _, err := RunIngest(CommandConfig{}, IngestConfig{Path: filepath.Join(tmp, "Movie.2020.mkv"), Shows: tmp}); err == nil {
+		t.Errorf("RunIngest() without a movies library succeeded, want error")
+	}
+}
+
+func TestRunIngestKeepsDownload(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		transfer IngestTransfer
+		path     string
+		files    []string
+		want     []string
+		absent   []string
+	}{
+		{"copy movie", TransferCopy, "Movie.2020.1080p", []string{"Movie.2020.1080p/movie.mkv", "Movie.2020.1080p/RARBG.txt"}, []string{"movies/Movie (2020)/Movie (2020).mkv"}, []string{"movies/Movie (2020)/RARBG.txt"}},
+		{"link season", TransferLink, "Show.Name.S02.1080p", []string{"Show.Name.S02.1080p/Show.Name.S02E01.mkv", "Show.Name.S02.1080p/Show.Name.S02E02.mkv", "Show.Name.S02.1080p/Sample/sample.mkv"}, []string{"shows/Show Name/Season 02/S02E01.mkv"}, []string{"shows/Show Name/Season 02/Sample"}},
+		{"copy episode", TransferCopy, "Show.Name.S01E05.720p.mkv", []string{"Show.Name.S01E05.720p.mkv", "Show.Name.S01E05.720p.en.srt"}, []string{"shows/Show Name/Season 01/S01E05.mkv", "shows/Show Name/Season 01/S01E05.en.srt"}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tmp := t.TempDir()
+			downloads := filepath.Join(tmp, "downloads")
+			for _, f := range tc.files {
+				path := filepath.Join(downloads, f)
+				os.MkdirAll(filepath.Dir(path), 0755)
+				os.WriteFile(path, []byte("data"), 0644)
+			}
+
+			result, err := RunIngest(CommandConfig{DeleteRules: JunkRules}, IngestConfig{
+				Path:     filepath.Join(downloads, tc.path),
+				Movies:   filepath.Join(tmp, "movies"),
+				Shows:    filepath.Join(tmp, "shows"),
+				Transfer: tc.transfer,
+			})
+			if err != nil || result.ExitCode() != IngestOK {
+				t.Fatalf("RunIngest() = exit %d (errors %v), %v; want exit 0", result.ExitCode(), result.Errors, err)
+			}
+			for _, f := range tc.files {
+				if _, err := os.Stat(filepath.Join(downloads, f)); err != nil {
+					t.Errorf("RunIngest() touched the download %s: %v", f, err)
+				}
+			}
+			for _, f := range tc.want {
+				if _, err := os.Stat(filepath.Join(tmp, f)); err != nil {
+					t.Errorf("RunIngest() did not create %s: %v", f, err)
+				}
+			}
+			for _, f := range tc.absent {
+				if _, err := os.Stat(filepath.Join(tmp, f)); !os.IsNotExist(err) {
+					t.Errorf("RunIngest() carried %s into the library", f)
+				}
+			}
+			if tc.transfer == TransferLink {
+				src, _ := os.Stat(filepath.Join(downloads, tc.files[0]))
+				dst, _ := os.Stat(filepath.Join(tmp, tc.want[0]))
+				if src == nil || dst == nil || !os.SameFile(src, dst) {
+					t.Errorf("RunIngest() did not hard link %s", tc.want[0])
+				}
+			}
+			if len(result.Files) == 0 || !strings.HasPrefix(result.Files[0].From, filepath.Join(downloads, tc.path)) {
+				t.Errorf("RunIngest() files = %v, want moves reported from the download", result.Files)
+			}
+			if len(result.Deleted) != 0 {
+				t.Errorf("RunIngest() reported deletions %v while keeping the download", result.Deleted)
+			}
+			for _, lib := range []string{"movies", "shows"} {
+				if matches, _ := filepath.Glob(filepath.Join(tmp, lib, ".title-tidy-ingest-*")); len(matches) != 0 {
+					t.Errorf("RunIngest() left staging folders %v", matches)
+				}
+			}
+		})
+	}
+}
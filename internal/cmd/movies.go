@@ -2,70 +2,117 @@ package cmd
 
 import (
 	"context"
+	"strings"
+
 	"github.com/Digital-Shane/title-tidy/internal/core"
 	"github.com/Digital-Shane/title-tidy/internal/media"
 	"github.com/Digital-Shane/treeview"
 )
 
 var MoviesCommand = CommandConfig{
-	maxDepth:    2,
+	maxDepth:    3,
 	includeDirs: true,
 	movieMode:   true,
 	preprocess:  MoviePreprocess,
 	annotate:    MovieAnnotate,
 }
 
-// MoviePreprocess groups standalone movie video files (and matching subtitles) into
+// MoviePreprocess groups standalone movie video files (and their companion files) into
 // virtual directories, so they can be materialized atomically during rename.
-// Matching for subtitles: the filename prefix before language + subtitle suffix must
-// exactly match the video filename without its extension.
+// Matching for companions: the filename prefix before the qualifiers (language,
+// commentary, chapters...) and extension must exactly match the video filename
+// without its extension. Stacked parts (Movie.2005.CD1.avi, Movie.2005.CD2.avi)
+// share one directory and are named "Movie (2005) - pt1.avi", "- pt2.avi". An
+// edition in the name is kept on the files: "Movie (1982) {edition-Final Cut}.mkv".
+// Loose extras of a movie ("Movie.2020-trailer.mkv") share its directory and keep
+// their own name with the extras suffix.
 func MoviePreprocess(nodes []*treeview.Node[treeview.FileInfo]) []*treeview.Node[treeview.FileInfo] {
 	type bundle struct {
 		dir *treeview.Node[treeview.FileInfo]
 	}
-	bundles := map[string]*bundle{} // base name (without extension) -> bundle
+	type member struct {
+		b       *bundle
+		newStem string // new video name without extension, edition and part suffix included
+	}
+	bundles := map[string]*bundle{} // base name (without extension and part token) -> bundle
+	members := map[string]member{}  // video base name -> its bundle and new name
+	titles := map[string]*bundle{}  // formatted title -> first bundle of that title
+	stacks := stackedParts(nodes)
 	var out []*treeview.Node[treeview.FileInfo]
 
-	// First pass: wrap loose video files
-	for _, n := range nodes {
-		if n.Data().IsDir() || !media.IsVideo(n.Name()) {
-			continue
-		}
+	// First pass: wrap loose video files (samples are left loose for MarkSamples).
+	// Extras are set aside so they can join the movie they belong to.
+	wrap := func(n *treeview.Node[treeview.FileInfo]) {
 		base := n.Name()
 		if ext := media.ExtractExtension(base); ext != "" {
 			base = base[:len(base)-len(ext)]
 		}
-		if _, exists := bundles[base]; !exists {
-			vd := treeview.NewNode(base, base, treeview.FileInfo{FileInfo: &SimpleFileInfo{name: base, isDir: true}, Path: base})
+		key, part := base, ""
+		if stem, num, ok := media.SplitStack(base); ok && stacks[stem][num] {
+			key, part = stem, media.FormatPart(num)
+		}
+		if _, exists := bundles[key]; !exists {
+			vd := treeview.NewNode(key, key, treeview.FileInfo{FileInfo: &SimpleFileInfo{name: key, isDir: true}, Path: key})
 			vm := core.EnsureMeta(vd)
 			vm.Type = core.MediaMovie
-			vm.NewName = media.FormatShowName(base)
+			vm.NewName = media.FormatShowName(key)
 			vm.IsVirtual = true
 			vm.NeedsDirectory = true
-			bundles[base] = &bundle{dir: vd}
+			bundles[key] = &bundle{dir: vd}
+			if titles[vm.NewName] == nil {
+				titles[vm.NewName] = bundles[key]
+			}
 		}
-		b := bundles[base]
+		b := bundles[key]
 		b.dir.AddChild(n)
+		stem := media.FormatShowName(key) + media.FormatEdition(media.ExtractEdition(key))
+		members[base] = member{b: b, newStem: stem + part}
 		cm := core.EnsureMeta(n)
 		cm.Type = core.MediaMovieFile
-		cm.NewName = media.FormatShowName(base) + media.ExtractExtension(n.Name())
+		cm.NewName = members[base].newStem + media.ExtractExtension(n.Name())
 	}
-
-	// Second pass: attach related subtitle files
+	var extras []*treeview.Node[treeview.FileInfo]
 	for _, n := range nodes {
-		if n.Data().IsDir() || !media.IsSubtitle(n.Name()) {
+		if n.Data().IsDir() || !media.IsVideo(n.Name()) || media.IsSample(n.Name()) {
 			continue
 		}
-		suffix := media.ExtractSubtitleSuffix(n.Name())
-		if suffix == "" { // defensive
+		if media.ExtraKindOf(n.Name()) != media.ExtraNone && !media.HasEpisodeMarker(n.Name()) {
+			extras = append(extras, n)
 			continue
 		}
-		base := n.Name()[:len(n.Name())-len(suffix)]
-		if b, ok := bundles[base]; ok {
-			b.dir.AddChild(n)
-			sm := core.EnsureMeta(n)
-			sm.Type = core.MediaMovieFile
-			sm.NewName = media.FormatShowName(base) + suffix
+		wrap(n)
+	}
+
+	// A loose extra ("Movie.2020.1080p-trailer.mkv") joins the movie of the same
+	// title with the extras suffix; without one it is taken for a movie itself
+	// ("Trailer.Park.Boys.2014.mkv").
+	for _, n := range extras {
+		ext := media.ExtractExtension(n.Name())
+		b := titles[media.FormatShowName(strings.TrimSuffix(n.Name(), ext))]
+		if b == nil {
+			wrap(n)
+			continue
+		}
+		b.dir.AddChild(n)
+		placeExtra(b.dir, n, media.ExtraKindOf(n.Name()), ExtrasSuffix)
+		members[strings.TrimSuffix(n.Name(), ext)] = member{b: b, newStem: strings.TrimSuffix(core.GetMeta(n).NewName, ext)}
+	}
+
+	// Second pass: attach companion files (subtitles, external audio, chapters...)
+	// whose name is a video's base name plus optional qualifiers and an extension
+	for _, n := range nodes {
+		if n.Data().IsDir() {
+			continue
+		}
+		for _, suffix := range media.CompanionSuffixes(n.Name()) {
+			base := n.Name()[:len(n.Name())-len(suffix)]
+			if mb, ok := members[base]; ok {
+				mb.b.dir.AddChild(n)
+				sm := core.EnsureMeta(n)
+				sm.Type = core.MediaMovieFile
+				sm.NewName = mb.newStem + media.NormalizeCompanionSuffix(suffix)
+				break
+			}
 		}
 	}
 
@@ -87,9 +134,36 @@ func MoviePreprocess(nodes []*treeview.Node[treeview.FileInfo]) []*treeview.Node
 	return out
 }
 
+// stackedParts returns the part numbers of the stacked videos among nodes by
+// stack name (the video name without its part token). Only names shared by at
+// least two videos with different parts form a stack, so a lone
+// "Mockingjay.Part.2" keeps its full name.
+func stackedParts(nodes []*treeview.Node[treeview.FileInfo]) map[string]map[int]bool {
+	stacks := map[string]map[int]bool{}
+	for _, n := range nodes {
+		if n.Data().IsDir() || !media.IsVideo(n.Name()) || media.IsSample(n.Name()) {
+			continue
+		}
+		if stem, part, ok := media.StackPart(n.Name()); ok {
+			if stacks[stem] == nil {
+				stacks[stem] = map[int]bool{}
+			}
+			stacks[stem][part] = true
+		}
+	}
+	for stem, parts := range stacks {
+		if len(parts) < 2 {
+			delete(stacks, stem)
+		}
+	}
+	return stacks
+}
+
 // MovieAnnotate adds metadata to any remaining movie directories / files not handled
 // during preprocess (e.g., pre-existing movie directories from the filesystem).
+// Subtitles in a Subs folder are moved up beside the movie's video.
 func MovieAnnotate(t *treeview.Tree[treeview.FileInfo]) {
+	stacks := map[*treeview.Node[treeview.FileInfo]]map[string]map[int]bool{} // movie dir -> its stacks
 	for ni := range t.All(context.Background()) {
 		if core.GetMeta(ni.Node) != nil { // already annotated
 			continue
@@ -100,6 +174,9 @@ func MovieAnnotate(t *treeview.Tree[treeview.FileInfo]) {
 			m.NewName = media.FormatShowName(ni.Node.Name())
 			continue
 		}
+		if ni.Node.Data().IsDir() { // nested folders keep their name; Subs are flattened below
+			continue
+		}
 		p := ni.Node.Parent()
 		pm := core.GetMeta(p)
 		if pm == nil || pm.NewName == "" {
@@ -107,10 +184,36 @@ func MovieAnnotate(t *treeview.Tree[treeview.FileInfo]) {
 		}
 		m := core.EnsureMeta(ni.Node)
 		m.Type = core.MediaMovieFile
-		if media.IsSubtitle(ni.Node.Name()) {
-			m.NewName = pm.NewName + media.ExtractSubtitleSuffix(ni.Node.Name())
+		stem := pm.NewName + media.FormatEdition(movieEdition(ni.Node))
+		if _, ok := stacks[p]; !ok {
+			stacks[p] = stackedParts(p.Children())
+		}
+		if name, part, ok := media.StackPart(ni.Node.Name()); ok && stacks[p][name][part] {
+			stem += media.FormatPart(part)
+		}
+		if media.IsCompanion(ni.Node.Name()) {
+			m.NewName = stem + media.ExtractCompanionSuffix(ni.Node.Name())
 		} else {
-			m.NewName = pm.NewName + media.ExtractExtension(ni.Node.Name())
+			m.NewName = stem + media.ExtractExtension(ni.Node.Name())
+		}
+	}
+	for _, movie := range t.Nodes() {
+		if mm := core.GetMeta(movie); mm == nil || mm.Type != core.MediaMovie || mm.IsVirtual {
+			continue
 		}
+		for _, c := range movie.Children() {
+			if c.Data().IsDir() && media.IsSubsDir(c.Name()) {
+				flattenSubs(movie, c, videosBelow(movie))
+			}
+		}
+	}
+}
+
+// movieEdition returns the edition named by a movie file, or by its folder when
+// the file name has none.
+func movieEdition(n *treeview.Node[treeview.FileInfo]) string {
+	if e := media.ExtractEdition(n.Name()); e != "" {
+		return e
 	}
+	return media.ExtractEdition(n.Parent().Name())
 }
@@ -1,9 +1,13 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/media"
 	"github.com/Digital-Shane/treeview"
 )
 
@@ -121,3 +125,313 @@ func TestMovieAnnotate_ChildWithoutParentNewName(t *testing.T) {
 		t.Errorf("MovieAnnotate should have skipped child when parent has no NewName")
 	}
 }
+
+func TestMoviePreprocess_Companions(t *testing.T) {
+	video := testNewFileNode("Movie.2020.1080p.mkv")
+	names := map[string]string{
+		"Movie.2020.1080p.en.ac3":             "Movie (2020).en.ac3",
+		"Movie.2020.1080p.commentary.mka":     "Movie (2020).commentary.mka",
+		"Movie.2020.1080p.chapters.xml":       "Movie (2020).chapters.xml",
+		"Movie.2020.1080p.en.idx":             "Movie (2020).en.idx",
+		"Movie.2020.1080p.en.sub":             "Movie (2020).en.sub",
+		"Movie.2020.1080p.English.forced.srt": "Movie (2020).en.forced.srt",
+		"Movie.2020.1080p.eng.sdh.srt":        "Movie (2020).eng.sdh.srt",
+	}
+	nodes := []*treeview.Node[treeview.FileInfo]{video}
+	for name := range names {
+		nodes = append(nodes, testNewFileNode(name))
+	}
+	other := testNewFileNode("Other.Film.ac3")
+	nodes = append(nodes, other)
+
+	tr := testNewTree(MoviePreprocess(nodes)...)
+	MovieAnnotate(tr)
+	PairVobSubs(tr)
+
+	dir := video.Parent()
+	if dir == nil || len(dir.Children()) != len(names)+1 {
+		t.Fatalf("MoviePreprocess() bundled %v, want the video and %d companions", dir, len(names))
+	}
+	for _, c := range dir.Children()[1:] {
+		if got := core.GetMeta(c).NewName; got != names[c.Name()] {
+			t.Errorf("MoviePreprocess(%s) NewName = %q, want %q", c.Name(), got, names[c.Name()])
+		}
+	}
+	if other.Parent() != nil {
+		t.Errorf("MoviePreprocess() bundled a companion without a matching video")
+	}
+}
+
+func TestPairVobSubs(t *testing.T) {
+	dir := testNewDirNode("Movie")
+	idx := testNewFileNode("movie.idx")
+	sub := testNewFileNode("movie.sub")
+	stray := testNewFileNode("other.sub")
+	dir.SetChildren([]*treeview.Node[treeview.FileInfo]{idx, sub, stray})
+	im := core.EnsureMeta(idx)
+	im.Type = core.MediaMovieFile
+	im.NewName = "Movie (2020).en.idx"
+	core.EnsureMeta(sub).NewName = "Movie (2020).sub"
+
+	PairVobSubs(testNewTree(dir))
+
+	if got := core.GetMeta(sub).NewName; got != "Movie (2020).en.sub" {
+		t.Errorf("PairVobSubs() sub NewName = %q, want %q", got, "Movie (2020).en.sub")
+	}
+	if core.GetMeta(stray) != nil {
+		t.Errorf("PairVobSubs() renamed a .sub belonging to another index")
+	}
+}
+
+func TestDetectSubtitleLanguages(t *testing.T) {
+	tmp := t.TempDir()
+	write := func(name, text string) *treeview.Node[treeview.FileInfo] {
+		path := filepath.Join(tmp, name)
+		os.WriteFile(path, []byte("1\n00:00:01,000 --> 00:00:04,000\n"+text+"\n"), 0644)
+		n := testNewFileNode(name)
+		n.Data().Path = path
+		core.EnsureMeta(n).NewName = "Movie (2020)" + filepath.Ext(name)
+		return n
+	}
+	english := write("movie.srt", strings.Repeat("Where were you last night? I waited for you at the station until the last train left. ", 4))
+	french := write("movie.1.srt", strings.Repeat("Où étais-tu hier soir ? Je t'ai attendu à la gare jusqu'au départ du dernier train. ", 4))
+	tagged := testNewFileNode("movie.de.srt")
+	core.EnsureMeta(tagged).NewName = "Movie (2020).de.srt"
+	dir := testNewDirNode("Movie")
+	dir.SetChildren([]*treeview.Node[treeview.FileInfo]{english, french, tagged})
+
+	DetectSubtitleLanguages(testNewTree(dir))
+
+	for n, want := range map[*treeview.Node[treeview.FileInfo]]string{english: "Movie (2020).en.srt", french: "Movie (2020).fr.srt", tagged: "Movie (2020).de.srt"} {
+		if got := core.GetMeta(n).NewName; got != want {
+			t.Errorf("DetectSubtitleLanguages(%s) NewName = %q, want %q", n.Name(), got, want)
+		}
+	}
+	if got := core.GetMeta(english).DetectedLanguage; got != "en" {
+		t.Errorf("DetectSubtitleLanguages() DetectedLanguage = %q, want en", got)
+	}
+	if got := core.GetMeta(tagged).DetectedLanguage; got != "" {
+		t.Errorf("DetectSubtitleLanguages() detected the language of a tagged subtitle: %q", got)
+	}
+}
+
+func TestMoviesCommandConvertsSubtitleEncoding(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	os.MkdirAll("Movie.2020", 0755)
+	os.WriteFile("Movie.2020/Movie.2020.mkv", []byte("video"), 0644)
+	os.WriteFile("Movie.2020/Movie.2020.ru.srt", []byte("1\n00:00:01,000 --> 00:00:02,000\n\xcf\xf0\xe8\xe2\xe5\xf2!\n"), 0644)
+	os.WriteFile("Movie.2020/Movie.2020.en.srt", []byte("1\n00:00:01,000 --> 00:00:02,000\nHello!\n"), 0644)
+
+	cfg := MoviesCommand
+	cfg.ConvertUTF8 = true
+	indexed, err := IndexTree(cfg, ".")
+	if err != nil {
+		t.Fatalf("IndexTree() error = %v", err)
+	}
+	tr := BuildPlan(cfg, UnwrapRoot(indexed))
+	if mm := core.GetMeta(findNodeByName(tr, "Movie.2020.ru.srt")); mm == nil || mm.ConvertFrom != "windows-1251" {
+		t.Fatalf("BuildPlan() ru subtitle meta = %#v, want conversion from windows-1251", mm)
+	}
+	if mm := core.GetMeta(findNodeByName(tr, "Movie.2020.en.srt")); mm.ConvertFrom != "" {
+		t.Errorf("BuildPlan() planned a conversion of a UTF-8 subtitle from %q", mm.ConvertFrom)
+	}
+
+	if rc := NewRenameModel(cfg, tr).RunAll(); rc.ErrorCount() != 0 {
+		t.Errorf("RunAll() errors = %d, want 0", rc.ErrorCount())
+	}
+	if got, _ := os.ReadFile("Movie (2020)/Movie (2020).ru.srt"); !strings.Contains(string(got), "Привет!") {
+		t.Errorf("RunAll() converted subtitle = %q, want UTF-8 text", got)
+	}
+	if _, err := os.Stat("Movie (2020)/Movie.2020.ru.srt.bak"); err != nil {
+		t.Errorf("RunAll() did not keep a backup: %v", err)
+	}
+}
+
+func TestMarkEncodingConversionsLargeUTF8(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	os.MkdirAll("Movie.2020", 0755)
+	os.WriteFile("Movie.2020/Movie.2020.mkv", []byte("video"), 0644)
+	// The two bytes of "é" straddle the end of the head read for detection.
+	head := "1\n00:00:01,000 --> 00:00:02,000\n"
+	sub := head + strings.Repeat("a", maxSubtitleRead-len(head)-1) + "é\n"
+	os.WriteFile("Movie.2020/Movie.2020.fr.srt", []byte(sub), 0644)
+
+	cfg := MoviesCommand
+	cfg.ConvertUTF8 = true
+	indexed, err := IndexTree(cfg, ".")
+	if err != nil {
+		t.Fatalf("IndexTree() error = %v", err)
+	}
+	tr := BuildPlan(cfg, UnwrapRoot(indexed))
+	if mm := core.GetMeta(findNodeByName(tr, "Movie.2020.fr.srt")); mm == nil || mm.ConvertFrom != "" {
+		t.Errorf("BuildPlan() fr subtitle meta = %#v, want no conversion of a UTF-8 subtitle", mm)
+	}
+}
+
+func TestMoviePreprocess_StackedParts(t *testing.T) {
+	cd1 := testNewFileNode("Movie.2005.CD1.avi")
+	cd2 := testNewFileNode("Movie.2005.CD2.avi")
+	sub2 := testNewFileNode("Movie.2005.CD2.en.srt")
+	out := MoviePreprocess([]*treeview.Node[treeview.FileInfo]{cd1, cd2, sub2})
+
+	if len(out) != 1 || cd1.Parent() != cd2.Parent() || sub2.Parent() != cd1.Parent() {
+		t.Fatalf("MoviePreprocess() = %d entries, want the parts in one directory", len(out))
+	}
+	if got := core.GetMeta(out[0]).NewName; got != "Movie (2005)" {
+		t.Errorf("MoviePreprocess() directory NewName = %q, want %q", got, "Movie (2005)")
+	}
+	for n, want := range map[*treeview.Node[treeview.FileInfo]]string{
+		cd1:  "Movie (2005) - pt1.avi",
+		cd2:  "Movie (2005) - pt2.avi",
+		sub2: "Movie (2005) - pt2.en.srt",
+	} {
+		if got := core.GetMeta(n).NewName; got != want {
+			t.Errorf("MoviePreprocess(%s) NewName = %q, want %q", n.Name(), got, want)
+		}
+	}
+}
+
+func TestMovieAnnotate_StackedParts(t *testing.T) {
+	dir := testNewDirNode("Movie.2005.DVDRip")
+	part1 := testNewFileNode("movie-cd1.avi")
+	part2 := testNewFileNode("movie-cd2.avi")
+	sub1 := testNewFileNode("movie-cd1.eng.srt")
+	dir.SetChildren([]*treeview.Node[treeview.FileInfo]{part1, part2, sub1})
+	MovieAnnotate(testNewTree(dir))
+
+	for n, want := range map[*treeview.Node[treeview.FileInfo]]string{
+		part1: "Movie (2005) - pt1.avi",
+		part2: "Movie (2005) - pt2.avi",
+		sub1:  "Movie (2005) - pt1.eng.srt",
+	} {
+		if got := core.GetMeta(n).NewName; got != want {
+			t.Errorf("MovieAnnotate(%s) NewName = %q, want %q", n.Name(), got, want)
+		}
+	}
+}
+
+func TestMoviePreprocess_SinglePartFilm(t *testing.T) {
+	film := testNewFileNode("The.Hunger.Games.Mockingjay.Part.2.mkv")
+	out := MoviePreprocess([]*treeview.Node[treeview.FileInfo]{film})
+
+	if len(out) != 1 {
+		t.Fatalf("MoviePreprocess() = %d entries, want 1", len(out))
+	}
+	if got := core.GetMeta(out[0]).NewName; got != "The Hunger Games Mockingjay Part 2" {
+		t.Errorf("MoviePreprocess() directory NewName = %q, want the full title", got)
+	}
+	if got := core.GetMeta(film).NewName; got != "The Hunger Games Mockingjay Part 2.mkv" {
+		t.Errorf("MoviePreprocess() NewName = %q, want %q", got, "The Hunger Games Mockingjay Part 2.mkv")
+	}
+}
+
+func TestMoviePreprocess_SeparatePartFilms(t *testing.T) {
+	part1 := testNewFileNode("Harry.Potter.and.the.Deathly.Hallows.Part.1.2010.mkv")
+	part2 := testNewFileNode("Harry.Potter.and.the.Deathly.Hallows.Part.2.2011.mkv")
+	out := MoviePreprocess([]*treeview.Node[treeview.FileInfo]{part1, part2})
+
+	if len(out) != 2 || part1.Parent() == part2.Parent() {
+		t.Fatalf("MoviePreprocess() = %d entries, want each film in its own directory", len(out))
+	}
+	if got := core.GetMeta(part2).NewName; got != "Harry Potter and the Deathly Hallows Part 2 (2011).mkv" {
+		t.Errorf("MoviePreprocess() NewName = %q, want the full title", got)
+	}
+}
+
+func TestMovieAnnotate_SinglePartFilms(t *testing.T) {
+	dir1 := testNewDirNode("X.Part.1")
+	film1 := testNewFileNode("X.Part.1.mkv")
+	dir1.AddChild(film1)
+	dir2 := testNewDirNode("X.Part.2")
+	film2 := testNewFileNode("X.Part.2.mkv")
+	sub2 := testNewFileNode("X.Part.2.en.srt")
+	dir2.SetChildren([]*treeview.Node[treeview.FileInfo]{film2, sub2})
+	MovieAnnotate(testNewTree(dir1, dir2))
+
+	for n, want := range map[*treeview.Node[treeview.FileInfo]]string{
+		film1: "X Part 1.mkv",
+		film2: "X Part 2.mkv",
+		sub2:  "X Part 2.en.srt",
+	} {
+		if got := core.GetMeta(n).NewName; got != want {
+			t.Errorf("MovieAnnotate(%s) NewName = %q, want %q", n.Name(), got, want)
+		}
+	}
+}
+
+func TestMoviePreprocess_Editions(t *testing.T) {
+	final := testNewFileNode("Blade.Runner.1982.Final.Cut.2160p.mkv")
+	theatrical := testNewFileNode("Blade.Runner.1982.Theatrical.1080p.mkv")
+	sub := testNewFileNode("Blade.Runner.1982.Final.Cut.2160p.en.srt")
+	out := MoviePreprocess([]*treeview.Node[treeview.FileInfo]{final, theatrical, sub})
+
+	for _, d := range out {
+		if got := core.GetMeta(d).NewName; got != "Blade Runner (1982)" {
+			t.Errorf("MoviePreprocess() directory NewName = %q, want %q", got, "Blade Runner (1982)")
+		}
+	}
+	for n, want := range map[*treeview.Node[treeview.FileInfo]]string{
+		final:      "Blade Runner (1982) {edition-Final Cut}.mkv",
+		theatrical: "Blade Runner (1982) {edition-Theatrical}.mkv",
+		sub:        "Blade Runner (1982) {edition-Final Cut}.en.srt",
+	} {
+		if got := core.GetMeta(n).NewName; got != want {
+			t.Errorf("MoviePreprocess(%s) NewName = %q, want %q", n.Name(), got, want)
+		}
+	}
+}
+
+func TestMovieAnnotate_Editions(t *testing.T) {
+	defer media.SetEditionStyle(media.EditionPlex)
+	media.SetEditionStyle(media.EditionJellyfin)
+	dir := testNewDirNode("Aliens.1986.DiRECTORS.CUT.1080p")
+	video := testNewFileNode("aliens.mkv")
+	sub := testNewFileNode("aliens.en.srt")
+	dir.SetChildren([]*treeview.Node[treeview.FileInfo]{video, sub})
+	MovieAnnotate(testNewTree(dir))
+
+	for n, want := range map[*treeview.Node[treeview.FileInfo]]string{
+		dir:   "Aliens (1986)",
+		video: "Aliens (1986) - Director's Cut.mkv",
+		sub:   "Aliens (1986) - Director's Cut.en.srt",
+	} {
+		if got := core.GetMeta(n).NewName; got != want {
+			t.Errorf("MovieAnnotate(%s) NewName = %q, want %q", n.Name(), got, want)
+		}
+	}
+}
+
+func TestMoviesCommandLooseTrailers(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	os.WriteFile("Movie.2020.1080p-trailer.mkv", []byte("trailer"), 0644)
+	os.WriteFile("Movie.2020.1080p.BluRay.mkv", []byte("the main feature"), 0644)
+	os.WriteFile("Movie.2020.Trailer.mkv", []byte("teaser"), 0644)
+	os.WriteFile("Movie.2020.Trailer.en.srt", []byte("sub"), 0644)
+
+	indexed, err := IndexTree(MoviesCommand, ".")
+	if err != nil {
+		t.Fatalf("IndexTree() error = %v", err)
+	}
+	if rc := NewRenameModel(MoviesCommand, BuildPlan(MoviesCommand, UnwrapRoot(indexed))).RunAll(); rc.ErrorCount() != 0 {
+		t.Errorf("RunAll() errors = %d, want 0", rc.ErrorCount())
+	}
+	for f, content := range map[string]string{
+		"Movie (2020)/Movie (2020).mkv":                  "the main feature",
+		"Movie (2020)/Movie.2020.1080p-trailer.mkv":      "trailer",
+		"Movie (2020)/Movie.2020.Trailer-trailer.mkv":    "teaser",
+		"Movie (2020)/Movie.2020.Trailer-trailer.en.srt": "sub",
+	} {
+		if data, err := os.ReadFile(f); err != nil || string(data) != content {
+			t.Errorf("RunAll() %s = %q (%v), want %q", f, data, err, content)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/media"
+	"github.com/Digital-Shane/treeview"
+)
+
+// DeleteRule describes a class of entries removed during rename. Every condition
+// that is set must match. Fields:
+//   - Glob: shell pattern matched case-insensitively against the base name.
+//   - Pattern: regular expression matched against the base name.
+//   - MinSize / MaxSize: inclusive byte bounds for files; zero disables a bound.
+//   - Kind: media category of files (video, subtitle, audio, sidecar, nfo, image, other).
+//   - Dir: the rule targets directories instead of files.
+//   - Empty: only directories with no entries on disk match.
+type DeleteRule struct {
+	Glob    string
+	Pattern *regexp.Regexp
+	MinSize int64
+	MaxSize int64
+	Kind    string
+	Dir     bool
+	Empty   bool
+}
+
+// JunkRules is the preset enabled by --junk: release clutter that never belongs
+// in a media library.
+var JunkRules = []DeleteRule{
+	{Glob: "*.txt"},
+	{Glob: "*.url"},
+	{Glob: "*.exe"},
+	{Pattern: regexp.MustCompile(`(?i)(?:^|[\s\.\-_])sample(?:[\s\.\-_]|$)`), Kind: "video"},
+	{Glob: "screens", Dir: true},
+	{Glob: "subs", Dir: true, Empty: true},
+}
+
+// Match reports whether the entry described by info satisfies every condition of the rule.
+func (r DeleteRule) Match(info treeview.FileInfo) bool {
+	if info.IsDir() != r.Dir {
+		return false
+	}
+	name := info.Name()
+	if r.Glob != "" {
+		if ok, _ := filepath.Match(strings.ToLower(r.Glob), strings.ToLower(name)); !ok {
+			return false
+		}
+	}
+	if r.Pattern != nil && !r.Pattern.MatchString(name) {
+		return false
+	}
+	if r.Kind != "" && fileKind(name) != r.Kind {
+		return false
+	}
+	if !r.Dir && r.MinSize > 0 && info.Size() < r.MinSize {
+		return false
+	}
+	if !r.Dir && r.MaxSize > 0 && info.Size() > r.MaxSize {
+		return false
+	}
+	if r.Empty {
+		entries, err := os.ReadDir(info.Path)
+		if err != nil || len(entries) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseDeleteRule parses a comma separated rule specification such as
+// "glob=*.txt", "re=(?i)sample,type=video,max=100MB" or "glob=Subs,dir,empty".
+func ParseDeleteRule(spec string) (DeleteRule, error) {
+	var r DeleteRule
+	for _, part := range strings.Split(spec, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		var err error
+		switch key {
+		case "glob":
+			_, err = filepath.Match(value, "")
+			r.Glob = value
+		case "re":
+			r.Pattern, err = regexp.Compile(value)
+		case "min":
+			r.MinSize, err = parseSize(value)
+		case "max":
+			r.MaxSize, err = parseSize(value)
+		case "type":
+			if value == "other" {
+				r.Kind = value
+			} else {
+				var c media.Category
+				c, err = media.ParseCategory(value)
+				r.Kind = c.String()
+			}
+		case "dir":
+			r.Dir = true
+		case "empty":
+			r.Dir, r.Empty = true, true
+		default:
+			err = fmt.Errorf("unknown key %q", key)
+		}
+		if err != nil {
+			return DeleteRule{}, fmt.Errorf("delete rule %q: %w", spec, err)
+		}
+	}
+	return r, nil
+}
+
+// parseSize converts values like "512", "50KB", "1.5G" into bytes.
+func parseSize(s string) (int64, error) {
+	u := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "B")
+	mult := int64(1)
+	if u != "" {
+		switch u[len(u)-1] {
+		case 'K':
+			mult = 1 << 10
+		case 'M':
+			mult = 1 << 20
+		case 'G':
+			mult = 1 << 30
+		}
+		if mult > 1 {
+			u = u[:len(u)-1]
+		}
+	}
+	f, err := strconv.ParseFloat(u, 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(f * float64(mult)), nil
+}
+
+// fileKind classifies a filename into the media kinds rules can select on: the
+// extension registry category, or "other" for unregistered extensions.
+func fileKind(name string) string {
+	if c := media.CategoryOf(name); c != media.CategoryNone {
+		return c.String()
+	}
+	return "other"
+}
+
+// MarkForDeletion traverses the tree and marks every entry matching one of rules
+// for deletion. A matched directory is removed as a whole, so its children are
+// dropped from the plan.
+func MarkForDeletion(t *treeview.Tree[treeview.FileInfo], rules []DeleteRule) {
+	if len(rules) == 0 {
+		return
+	}
+	for ni := range t.All(context.Background()) {
+		for _, r := range rules {
+			if !r.Match(*ni.Node.Data()) {
+				continue
+			}
+			meta := core.EnsureMeta(ni.Node)
+			meta.MarkedForDeletion = true
+			if ni.Node.Data().IsDir() {
+				ni.Node.SetChildren(nil)
+			}
+			break
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/treeview"
+)
+
+func TestParseDeleteRule(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    DeleteRule
+		wantErr bool
+	}{
+		{spec: "glob=*.txt", want: DeleteRule{Glob: "*.txt"}},
+		{spec: "type=video,min=1k,max=1.5MB", want: DeleteRule{Kind: "video", MinSize: 1024, MaxSize: 1572864}},
+		{spec: "glob=Subs,empty", want: DeleteRule{Glob: "Subs", Dir: true, Empty: true}},
+		{spec: "glob=Screens,dir", want: DeleteRule{Glob: "Screens", Dir: true}},
+		{spec: "type=audio", want: DeleteRule{Kind: "audio"}},
+		{spec: "type=music", wantErr: true},
+		{spec: "max=lots", wantErr: true},
+		{spec: "re=(", wantErr: true},
+		{spec: "color=red", wantErr: true},
+	}
+	for _, tc := range tests {
+		got, err := ParseDeleteRule(tc.spec)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ParseDeleteRule(%q) error = %v, wantErr %v", tc.spec, err, tc.wantErr)
+			continue
+		}
+		if err == nil && (got.Glob != tc.want.Glob || got.Kind != tc.want.Kind || got.MinSize != tc.want.MinSize ||
+			got.MaxSize != tc.want.MaxSize || got.Dir != tc.want.Dir || got.Empty != tc.want.Empty) {
+			t.Errorf("ParseDeleteRule(%q) = %+v, want %+v", tc.spec, got, tc.want)
+		}
+	}
+	r, err := ParseDeleteRule("re=(?i)^rarbg")
+	if err != nil || r.Pattern == nil || !r.Pattern.MatchString("RARBG.txt") {
+		t.Errorf("ParseDeleteRule(re) = (%+v,%v), want compiled pattern", r, err)
+	}
+}
+
+func TestJunkRules(t *testing.T) {
+	tmp := t.TempDir()
+	os.MkdirAll(filepath.Join(tmp, "Subs"), 0755)
+	os.MkdirAll(filepath.Join(tmp, "FullSubs", "x"), 0755)
+	os.MkdirAll(filepath.Join(tmp, "Screens"), 0755)
+	info := func(name string, isDir bool) treeview.FileInfo {
+		return treeview.FileInfo{FileInfo: &SimpleFileInfo{name: name, isDir: isDir}, Path: filepath.Join(tmp, name)}
+	}
+	tests := []struct {
+		info treeview.FileInfo
+		want bool
+	}{
+		{info("RARBG.txt", false), true},
+		{info("Visit.url", false), true},
+		{info("setup.exe", false), true},
+		{info("movie-sample.mkv", false), true},
+		{info("Sample.mkv", false), true},
+		{info("sample.srt", false), false},
+		{info("Samples.Of.Life.2020.mkv", false), false},
+		{info("Screens", true), true},
+		{info("Subs", true), true},
+		{info("FullSubs", true), false},
+		{info("movie.mkv", false), false},
+	}
+	for _, tc := range tests {
+		got := false
+		for _, r := range JunkRules {
+			if r.Match(tc.info) {
+				got = true
+			}
+		}
+		if got != tc.want {
+			t.Errorf("JunkRules match %q = %v, want %v", tc.info.Name(), got, tc.want)
+		}
+	}
+}
+
+func TestMarkForDeletion_Directories(t *testing.T) {
+	screens := testNewDirNode("Screens")
+	screens.AddChild(testNewFileNode("shot1.jpg"))
+	movie := testNewDirNode("Movie.2020")
+	movie.AddChild(testNewFileNode("Movie.2020.mkv"))
+	movie.AddChild(screens)
+	movie.AddChild(testNewFileNode("RARBG.txt"))
+	tr := testNewTree(movie)
+
+	MarkForDeletion(tr, JunkRules)
+
+	if mm := core.GetMeta(screens); mm == nil || !mm.MarkedForDeletion {
+		t.Errorf("MarkForDeletion(Screens) not marked")
+	}
+	if len(screens.Children()) != 0 {
+		t.Errorf("MarkForDeletion(Screens) kept %d children, want 0", len(screens.Children()))
+	}
+	if mm := core.GetMeta(findNodeByName(tr, "RARBG.txt")); mm == nil || !mm.MarkedForDeletion {
+		t.Errorf("MarkForDeletion(RARBG.txt) not marked")
+	}
+	if mm := core.GetMeta(findNodeByName(tr, "Movie.2020.mkv")); mm != nil && mm.MarkedForDeletion {
+		t.Errorf("MarkForDeletion(video) unexpectedly marked")
+	}
+}
+
+func TestCreateMediaFilterWithRules(t *testing.T) {
+	f := CreateMediaFilter(false, JunkRules...)
+	assertBool(t, f(testTreeviewFileInfo("RARBG.txt", false)), true, "CreateMediaFilter(rules)(RARBG.txt)")
+	assertBool(t, f(testTreeviewFileInfo("readme.md", false)), false, "CreateMediaFilter(rules)(readme.md)")
+	assertBool(t, f(testTreeviewFileInfo("Screens", true)), false, "CreateMediaFilter(rules, no dirs)(Screens)")
+}
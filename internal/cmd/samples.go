@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/media"
+	"github.com/Digital-Shane/treeview"
+)
+
+// MarkSamples classifies release samples so they are excluded from renaming. A
+// video is a sample when its name carries a "sample" token, when it lives in a
+// Sample/ folder, or, with a ratio above 0, when it sits in a movie folder and is
+// smaller than ratio times the largest video there. The size check stays out of
+// season folders, where an SD episode may sit beside a 4K one. Extras are never
+// samples. Samples are marked for deletion when deleteSamples is set.
+func MarkSamples(t *treeview.Tree[treeview.FileInfo], ratio float64, deleteSamples bool) {
+	for ni := range t.All(context.Background()) {
+		n := ni.Node
+		if mm := core.GetMeta(n); (mm != nil && mm.Type == core.MediaExtra) || !isSample(n, ratio) {
+			continue
+		}
+		mm := core.EnsureMeta(n)
+		mm.Type = core.MediaSample
+		mm.NewName = ""
+		mm.MarkedForDeletion = deleteSamples
+		if deleteSamples && n.Data().IsDir() {
+			n.SetChildren(nil) // removed together with the folder
+		}
+	}
+}
+
+// isSample applies the name, folder and relative size checks to a single node.
+func isSample(n *treeview.Node[treeview.FileInfo], ratio float64) bool {
+	name := n.Name()
+	if n.Data().IsDir() {
+		return media.IsSampleDir(name)
+	}
+	if !media.IsVideo(name) {
+		return false
+	}
+	if media.IsSample(name) {
+		return true
+	}
+	p := n.Parent()
+	if p == nil {
+		return false
+	}
+	if media.IsSampleDir(p.Name()) {
+		return true
+	}
+	size := n.Data().Size()
+	if pm := core.GetMeta(p); ratio <= 0 || size <= 0 || pm == nil || pm.Type != core.MediaMovie {
+		return false
+	}
+	var largest int64
+	for _, sib := range p.Children() {
+		if !sib.Data().IsDir() && media.IsVideo(sib.Name()) {
+			largest = max(largest, sib.Data().Size())
+		}
+	}
+	return float64(size) < ratio*float64(largest)
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/treeview"
+)
+
+// sizedFileInfo reports a fixed size so the relative size check can be exercised.
+type sizedFileInfo struct {
+	SimpleFileInfo
+	size int64
+}
+
+func (s *sizedFileInfo) Size() int64 { return s.size }
+
+func testNewSizedFileNode(name string, size int64) *treeview.Node[treeview.FileInfo] {
+	fi := &sizedFileInfo{SimpleFileInfo{name: name}, size}
+	return treeview.NewNode(name, name, treeview.FileInfo{FileInfo: fi, Path: name})
+}
+
+func TestMarkSamples(t *testing.T) {
+	dir := testNewDirNode("Movie.2020")
+	main := testNewSizedFileNode("Movie.2020.1080p.mkv", 4<<30)
+	named := testNewSizedFileNode("movie-sample.mkv", 0)
+	small := testNewSizedFileNode("Movie.2020.720p.mkv", 50<<20)
+	sub := testNewFileNode("Movie.2020.en.srt")
+	sampleDir := testNewDirNode("Sample")
+	inSampleDir := testNewSizedFileNode("clip.mkv", 0)
+	sampleDir.AddChild(inSampleDir)
+	dir.SetChildren([]*treeview.Node[treeview.FileInfo]{main, named, small, sub, sampleDir})
+	tr := testNewTree(dir)
+	MovieAnnotate(tr)
+
+	MarkSamples(tr, 0.1, false)
+
+	for _, tc := range []struct {
+		node *treeview.Node[treeview.FileInfo]
+		want bool
+	}{{main, false}, {named, true}, {small, true}, {sub, false}, {sampleDir, true}, {inSampleDir, true}} {
+		mm := core.GetMeta(tc.node)
+		got := mm != nil && mm.Type == core.MediaSample
+		if got != tc.want {
+			t.Errorf("MarkSamples(%s) sample = %v, want %v", tc.node.Name(), got, tc.want)
+		}
+		if got && (mm.NewName != "" || mm.MarkedForDeletion) {
+			t.Errorf("MarkSamples(%s) NewName = %q, deleted = %v; want untouched sample", tc.node.Name(), mm.NewName, mm.MarkedForDeletion)
+		}
+	}
+}
+
+func TestMarkSamplesSizeCheck(t *testing.T) {
+	movie := testNewDirNode("Movie.2020")
+	small := testNewSizedFileNode("Movie.2020.720p.mkv", 50<<20)
+	movie.SetChildren([]*treeview.Node[treeview.FileInfo]{testNewSizedFileNode("Movie.2020.2160p.mkv", 4<<30), small})
+	season := testNewDirNode("Season 01")
+	sd := testNewSizedFileNode("Show.S01E01.480p.mkv", 100<<20)
+	season.SetChildren([]*treeview.Node[treeview.FileInfo]{sd, testNewSizedFileNode("Show.S01E02.2160p.mkv", 4<<30)})
+	core.EnsureMeta(movie).Type = core.MediaMovie
+	core.EnsureMeta(season).Type = core.MediaSeason
+	tr := testNewTree(movie, season)
+
+	MarkSamples(tr, 0, false)
+	if mm := core.GetMeta(small); mm != nil && mm.Type == core.MediaSample {
+		t.Errorf("MarkSamples(ratio 0) flagged %s by size", small.Name())
+	}
+	MarkSamples(tr, 0.1, false)
+	if mm := core.GetMeta(small); mm == nil || mm.Type != core.MediaSample {
+		t.Errorf("MarkSamples(ratio 0.1) did not flag the small video of a movie folder")
+	}
+	if mm := core.GetMeta(sd); mm != nil && mm.Type == core.MediaSample {
+		t.Errorf("MarkSamples(ratio 0.1) flagged the SD episode %s of a season folder", sd.Name())
+	}
+}
+
+func TestMarkSamplesDelete(t *testing.T) {
+	dir := testNewDirNode("Movie.2020")
+	sampleDir := testNewDirNode("Sample")
+	sampleDir.AddChild(testNewFileNode("clip.mkv"))
+	named := testNewFileNode("Movie.2020.sample.mkv")
+	dir.SetChildren([]*treeview.Node[treeview.FileInfo]{testNewFileNode("Movie.2020.mkv"), named, sampleDir})
+	tr := testNewTree(dir)
+
+	MarkSamples(tr, 0, true)
+
+	for _, n := range []*treeview.Node[treeview.FileInfo]{named, sampleDir} {
+		if mm := core.GetMeta(n); mm == nil || !mm.MarkedForDeletion {
+			t.Errorf("MarkSamples(delete) left %s unmarked", n.Name())
+		}
+	}
+	if len(sampleDir.Children()) != 0 {
+		t.Errorf("MarkSamples(delete) kept %d children under deleted sample folder", len(sampleDir.Children()))
+	}
+}
+
+func TestMoviePreprocessSkipsSamples(t *testing.T) {
+	out := MoviePreprocess([]*treeview.Node[treeview.FileInfo]{
+		testNewFileNode("Movie.2020.mkv"),
+		testNewFileNode("Movie.2020.sample.mkv"),
+	})
+	for _, n := range out {
+		if n.Name() == "Movie.2020.sample.mkv" {
+			return
+		}
+	}
+	t.Errorf("MoviePreprocess wrapped the sample into a movie folder: %v", out)
+}
@@ -10,6 +10,7 @@ import (
 var SeasonsCommand = CommandConfig{
 	maxDepth:    2,
 	includeDirs: true,
+	deepScan:    true,
 	annotate: func(t *treeview.Tree[treeview.FileInfo]) {
 		for ni := range t.All(context.Background()) {
 			m := core.EnsureMeta(ni.Node)
@@ -21,5 +22,8 @@ var SeasonsCommand = CommandConfig{
 				m.NewName = media.FormatEpisodeName(ni.Node.Name(), ni.Node)
 			}
 		}
+		for _, season := range t.Nodes() {
+			flattenSubdirs(season)
+		}
 	},
 }
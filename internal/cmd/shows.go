@@ -10,6 +10,7 @@ import (
 var ShowsCommand = CommandConfig{
 	maxDepth:    3,
 	includeDirs: true,
+	deepScan:    true,
 	annotate: func(t *treeview.Tree[treeview.FileInfo]) {
 		for ni := range t.All(context.Background()) {
 			m := core.EnsureMeta(ni.Node)
@@ -25,5 +26,8 @@ var ShowsCommand = CommandConfig{
 				m.NewName = media.FormatEpisodeName(ni.Node.Name(), ni.Node)
 			}
 		}
+		for _, show := range t.Nodes() {
+			FlattenShow(show)
+		}
 	},
 }
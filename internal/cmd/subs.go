@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/media"
+	"github.com/Digital-Shane/treeview"
+)
+
+// flattenSubs moves the subtitles of a release Subs folder (Movie/Subs/2_English.srt,
+// Show S01/Subs/S01E01/3_eng.srt) into dest beside the video they belong to, named
+// after that video with the language and flags read from the subtitle name.
+// Subtitles that match none of videos are left where they are, and so is the
+// folder holding them; only folders whose subtitles all move are flattened.
+func flattenSubs(dest, subs *treeview.Node[treeview.FileInfo], videos []*treeview.Node[treeview.FileInfo]) {
+	taken := map[string]bool{}
+	emptied := true
+	for _, c := range subs.Children() {
+		if !c.Data().IsDir() {
+			if !placeSub(dest, c, subsVideo(c.Name(), "", videos), taken) {
+				emptied = false
+			}
+			continue
+		}
+		placed := true
+		for _, f := range c.Children() {
+			if f.Data().IsDir() || !placeSub(dest, f, subsVideo(f.Name(), c.Name(), videos), taken) {
+				placed = false
+			}
+		}
+		keepOrFlatten(c, placed)
+		emptied = emptied && placed
+	}
+	keepOrFlatten(subs, emptied)
+}
+
+// keepOrFlatten marks dir flattened when everything in it moves, and otherwise
+// drops its annotation so it stays under its own name.
+func keepOrFlatten(dir *treeview.Node[treeview.FileInfo], emptied bool) {
+	if emptied {
+		markFlattened(dir)
+	} else if extra := dir.Data().Extra; extra != nil {
+		delete(extra, "meta")
+	}
+}
+
+// placeSub annotates sub to be renamed after video and moved into dest. Without
+// a video any previous annotation is dropped so the file stays untouched. taken
+// holds the names already given in dest: a second track of the same language
+// (3_English.srt after 2_English.srt) keeps its track number, "Movie.3.en.srt".
+// It reports whether sub moves.
+func placeSub(dest, sub, video *treeview.Node[treeview.FileInfo], taken map[string]bool) bool {
+	var vm *core.MediaMeta
+	if video != nil {
+		vm = core.GetMeta(video)
+	}
+	if vm == nil || vm.NewName == "" || !media.IsSubtitle(sub.Name()) {
+		if extra := sub.Data().Extra; extra != nil {
+			delete(extra, "meta")
+		}
+		return false
+	}
+	ext := media.ExtractExtension(sub.Name())
+	m := core.EnsureMeta(sub)
+	m.Type = vm.Type
+	stem := strings.TrimSuffix(vm.NewName, media.ExtractExtension(vm.NewName))
+	suffix := media.GuessSubtitleTags(sub.Name()).Suffix(ext)
+	name := stem + suffix
+	if taken[strings.ToLower(name)] {
+		track := media.SubtitleTrack(sub.Name())
+		for i := 2; track == "" || taken[strings.ToLower(stem+"."+track+suffix)]; i++ {
+			track = strconv.Itoa(i)
+		}
+		name = stem + "." + track + suffix
+	}
+	taken[strings.ToLower(name)] = true
+	m.NewName = name
+	m.DestDir = dest.Data().Path
+	return true
+}
+
+// subsVideo picks the video a subtitle from a Subs folder belongs to: the video
+// named like its sub-folder (or the subtitle itself), or the only video there is.
+func subsVideo(name, folder string, videos []*treeview.Node[treeview.FileInfo]) *treeview.Node[treeview.FileInfo] {
+	for _, key := range []string{folder, name} {
+		if key == "" {
+			continue
+		}
+		for _, v := range videos {
+			if subsKeyMatches(key, v.Name()) {
+				return v
+			}
+		}
+	}
+	if len(videos) == 1 {
+		return videos[0]
+	}
+	return nil
+}
+
+// subsKeyMatches reports whether key (a folder or subtitle name) names video,
+// either by its file name without extension or by the same episode marker.
+func subsKeyMatches(key, video string) bool {
+	stem := strings.ToLower(strings.TrimSuffix(video, media.ExtractExtension(video)))
+	if low := strings.ToLower(key); low == stem || strings.HasPrefix(low, stem+".") {
+		return true
+	}
+	if !media.HasEpisodeMarker(key) || !media.HasEpisodeMarker(video) {
+		return false
+	}
+	ks, ke, ok1 := media.ParseSeasonEpisode(key, nil)
+	vs, ve, ok2 := media.ParseSeasonEpisode(video, nil)
+	return ok1 && ok2 && ks == vs && ke == ve
+}
+
+// videosBelow returns the videos in dir and its sub-folders, skipping Subs,
+// sample and extras folders. Videos named as extras (Movie-trailer.mkv) are left
+// out too, unless nothing else is left.
+func videosBelow(dir *treeview.Node[treeview.FileInfo]) []*treeview.Node[treeview.FileInfo] {
+	var videos, extras []*treeview.Node[treeview.FileInfo]
+	var walk func(*treeview.Node[treeview.FileInfo])
+	walk = func(d *treeview.Node[treeview.FileInfo]) {
+		for _, c := range d.Children() {
+			_, extrasDir := media.ExtrasDirKind(c.Name())
+			switch {
+			case c.Data().IsDir() && !media.IsSubsDir(c.Name()) && !media.IsSampleDir(c.Name()) && !extrasDir:
+				walk(c)
+			case c.Data().IsDir() || !media.IsVideo(c.Name()) || media.IsSample(c.Name()):
+			case media.ExtraKindOf(c.Name()) != media.ExtraNone && !media.HasEpisodeMarker(c.Name()):
+				extras = append(extras, c)
+			default:
+				videos = append(videos, c)
+			}
+		}
+	}
+	walk(dir)
+	if len(videos) == 0 {
+		return extras
+	}
+	return videos
+}
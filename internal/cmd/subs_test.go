@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+)
+
+func TestMoviesCommandFlattensSubs(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	for _, f := range []string{
+		"Movie.2020.1080p/Movie.2020.1080p.mkv",
+		"Movie.2020.1080p/Subs/2_English.srt",
+		"Movie.2020.1080p/Subs/3_eng.sdh.srt",
+		"Movie.2020.1080p/Subs/Forced.French.srt",
+		"Movie.2020.1080p/Subs/readme.txt",
+	} {
+		os.MkdirAll(filepath.Dir(f), 0755)
+		os.WriteFile(f, []byte("data"), 0644)
+	}
+
+	indexed, err := IndexTree(MoviesCommand, ".")
+	if err != nil {
+		t.Fatalf("IndexTree() error = %v", err)
+	}
+	tr := BuildPlan(MoviesCommand, UnwrapRoot(indexed))
+	if mm := core.GetMeta(findNodeByName(tr, "Subs")); mm == nil || mm.Type != core.MediaFlattened {
+		t.Errorf("BuildPlan() Subs meta = %#v, want flattened", mm)
+	}
+
+	rc := NewRenameModel(MoviesCommand, tr).RunAll()
+	if rc.ErrorCount() != 0 {
+		t.Errorf("RunAll() errors = %d, want 0", rc.ErrorCount())
+	}
+	for _, want := range []string{
+		"Movie (2020)/Movie (2020).mkv",
+		"Movie (2020)/Movie (2020).en.srt",
+		"Movie (2020)/Movie (2020).eng.sdh.srt",
+		"Movie (2020)/Movie (2020).fr.forced.srt",
+		"Movie (2020)/Subs/readme.txt",
+	} {
+		if _, err := os.Stat(want); err != nil {
+			t.Errorf("RunAll() did not create %s: %v", want, err)
+		}
+	}
+}
+
+func TestMoviesCommandFlattensSameLanguageSubs(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	for _, f := range []string{
+		"Movie.2020/Movie.2020.mkv",
+		"Movie.2020/Subs/2_English.srt",
+		"Movie.2020/Subs/3_English.srt",
+		"Movie.2020/Subs/English.srt",
+	} {
+		os.MkdirAll(filepath.Dir(f), 0755)
+		os.WriteFile(f, []byte("data"), 0644)
+	}
+
+	indexed, err := IndexTree(MoviesCommand, ".")
+	if err != nil {
+		t.Fatalf("IndexTree() error = %v", err)
+	}
+	if rc := NewRenameModel(MoviesCommand, BuildPlan(MoviesCommand, UnwrapRoot(indexed))).RunAll(); rc.ErrorCount() != 0 {
+		t.Errorf("RunAll() errors = %d, want 0", rc.ErrorCount())
+	}
+	for _, want := range []string{
+		"Movie (2020)/Movie (2020).en.srt",
+		"Movie (2020)/Movie (2020).3.en.srt",
+		"Movie (2020)/Movie (2020).2.en.srt",
+	} {
+		if _, err := os.Stat(want); err != nil {
+			t.Errorf("RunAll() did not create %s: %v", want, err)
+		}
+	}
+}
+
+func TestSeasonsCommandFlattensSubs(t *testing.T) {
+	season := testNewDirNode("Show.S01")
+	ep1 := testNewFileNode("Show.S01E01.mkv")
+	ep2 := testNewFileNode("Show.S01E02.mkv")
+	subs := testNewDirNode("Subs")
+	folder := testNewDirNode("Show.S01E01")
+	byFolder := testNewFileNode("3_eng.srt")
+	folder.AddChild(byFolder)
+	byName := testNewFileNode("Show.S01E02.English.srt")
+	stray := testNewFileNode("2_English.srt")
+	subs.AddChild(folder)
+	subs.AddChild(byName)
+	subs.AddChild(stray)
+	season.AddChild(ep1)
+	season.AddChild(ep2)
+	season.AddChild(subs)
+	tr := testNewTree(season)
+
+	SeasonsCommand.annotate(tr)
+
+	for node, want := range map[string]string{"3_eng.srt": "S01E01.eng.srt", "Show.S01E02.English.srt": "S01E02.en.srt"} {
+		mm := core.GetMeta(findNodeByName(tr, node))
+		if mm == nil || mm.NewName != want || mm.DestDir != season.Data().Path {
+			t.Errorf("SeasonsCommand annotate(%s) = %#v, want %q in the season", node, mm, want)
+		}
+	}
+	if core.GetMeta(stray) != nil {
+		t.Errorf("SeasonsCommand annotate() renamed a subtitle matching no episode")
+	}
+	if mm := core.GetMeta(folder); mm == nil || mm.Type != core.MediaFlattened {
+		t.Errorf("SeasonsCommand annotate() Subs sub-folder meta = %#v, want flattened", mm)
+	}
+}
+
+func TestMoviesCommandFlattensSubsBesideSampleAndTrailer(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	for f, content := range map[string]string{
+		"Movie.2020/Movie.2020.mkv":          "the main feature",
+		"Movie.2020/Movie.2020-trailer.mkv":  "trailer",
+		"Movie.2020/Sample/movie-sample.mkv": "sample",
+		"Movie.2020/Subs/2_English.srt":      "sub",
+	} {
+		os.MkdirAll(filepath.Dir(f), 0755)
+		os.WriteFile(f, []byte(content), 0644)
+	}
+
+	indexed, err := IndexTree(MoviesCommand, ".")
+	if err != nil {
+		t.Fatalf("IndexTree() error = %v", err)
+	}
+	if rc := NewRenameModel(MoviesCommand, BuildPlan(MoviesCommand, UnwrapRoot(indexed))).RunAll(); rc.ErrorCount() != 0 {
+		t.Errorf("RunAll() errors = %d, want 0", rc.ErrorCount())
+	}
+	if _, err := os.Stat("Movie (2020)/Movie (2020).en.srt"); err != nil {
+		t.Errorf("RunAll() did not flatten the subtitle: %v", err)
+	}
+}
+
+func TestMoviesCommandKeepsSubsWithUnmatchedSubtitles(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	for _, f := range []string{
+		"Movie.2020/Movie.2020.1080p.mkv",
+		"Movie.2020/Movie.2020.720p.mkv",
+		"Movie.2020/Subs/2_English.srt",
+	} {
+		os.MkdirAll(filepath.Dir(f), 0755)
+		os.WriteFile(f, []byte("data"), 0644)
+	}
+
+	indexed, err := IndexTree(MoviesCommand, ".")
+	if err != nil {
+		t.Fatalf("IndexTree() error = %v", err)
+	}
+	tr := BuildPlan(MoviesCommand, UnwrapRoot(indexed))
+	if mm := core.GetMeta(findNodeByName(tr, "Subs")); mm != nil && mm.Type == core.MediaFlattened {
+		t.Errorf("BuildPlan() flattened Subs although its subtitle matches no video")
+	}
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/media"
+	"github.com/Digital-Shane/treeview"
+)
+
+// UpgradePolicy selects how a rename onto an existing file is handled.
+type UpgradePolicy int
+
+const (
+	UpgradeOff    UpgradePolicy = iota // Existing destination is reported as an error
+	UpgradeDelete                      // Worse copy is removed
+	UpgradeSuffix                      // Worse copy is kept: an existing one renamed with UpgradeKeepSuffix, an incoming one left as it is
+)
+
+// UpgradeKeepSuffix is inserted before the extension of an existing copy that is
+// kept beside its replacement.
+const UpgradeKeepSuffix = ".old"
+
+// ParseUpgradePolicy maps a flag value to an UpgradePolicy.
+func ParseUpgradePolicy(s string) (UpgradePolicy, error) {
+	switch s {
+	case "", "off":
+		return UpgradeOff, nil
+	case "delete":
+		return UpgradeDelete, nil
+	case "suffix":
+		return UpgradeSuffix, nil
+	}
+	return UpgradeOff, fmt.Errorf("unknown upgrade policy %q (want off, delete or suffix)", s)
+}
+
+// MarkUpgrades compares every file whose proposed destination already exists on
+// disk against that existing file. This includes children of virtual directories
+// and files inside a directory merged into an existing one; the latter are then
+// moved straight into the merge target so the decision applies there. Quality is
+// parsed from both names (resolution, source, codec); when that is inconclusive
+// and compareSize is set the larger file wins, and otherwise a copy of known
+// quality beats one whose name carries none (usually the existing copy, already
+// renamed to a clean name). Ties between known qualities keep the existing copy.
+// When neither name carries a quality the incoming copy takes the name and the
+// existing one is kept with UpgradeKeepSuffix, whatever the policy.
+func MarkUpgrades(t *treeview.Tree[treeview.FileInfo], policy UpgradePolicy, compareSize bool) {
+	if policy == UpgradeOff {
+		return
+	}
+	for ni := range t.All(context.Background()) {
+		n := ni.Node
+		mm := core.GetMeta(n)
+		if mm == nil || n.Data().IsDir() || mm.MarkedForDeletion {
+			continue
+		}
+		dest, merged := upgradeTarget(n, mm)
+		if dest == "" {
+			continue
+		}
+		existing, err := os.Stat(dest)
+		if err != nil || existing.IsDir() {
+			continue
+		}
+		if merged {
+			mm.DestDir, mm.NewName = filepath.Dir(dest), filepath.Base(dest)
+		}
+		incoming := media.ParseQuality(n.Name())
+		current := media.ParseQuality(existing.Name())
+		cmp := incoming.Compare(current)
+		reason := fmt.Sprintf("%s vs %s", incoming, current)
+		if cmp == 0 && compareSize && n.Data().Size() != existing.Size() {
+			if n.Data().Size() > existing.Size() {
+				cmp = 1
+			} else {
+				cmp = -1
+			}
+			reason = fmt.Sprintf("%d vs %d bytes", n.Data().Size(), existing.Size())
+		}
+		if cmp == 0 && incoming.Known() != current.Known() {
+			if cmp = -1; incoming.Known() {
+				cmp = 1
+			}
+		}
+		switch {
+		case cmp > 0:
+			mm.Upgrade = core.UpgradeReplaceExisting
+		case cmp < 0 || incoming.Known():
+			mm.Upgrade = core.UpgradeDiscardIncoming
+		default:
+			mm.Upgrade = core.UpgradeReplaceExisting
+			mm.KeepSuffix = UpgradeKeepSuffix
+			reason += ", keeping both"
+		}
+		mm.UpgradeReason = reason
+		if policy == UpgradeSuffix {
+			if mm.Upgrade == core.UpgradeDiscardIncoming {
+				mm.Upgrade = core.UpgradeKeepIncoming
+			} else {
+				mm.KeepSuffix = UpgradeKeepSuffix
+			}
+		}
+	}
+}
+
+// upgradeTarget returns the path n would be moved onto, and whether that path is
+// inside the merge target of one of its ancestors. Children of virtual
+// directories land below their parent's planned path. Other files are checked
+// at their own destination first, then in the directory their folder merges into.
+func upgradeTarget(n *treeview.Node[treeview.FileInfo], mm *core.MediaMeta) (string, bool) {
+	if p := n.Parent(); p != nil {
+		if pm := core.GetMeta(p); pm != nil && pm.IsVirtual {
+			if mm.NewName == "" {
+				return "", false
+			}
+			return plannedPath(n), false
+		}
+	}
+	dest := ""
+	if mm.NeedsRename(n.Name()) {
+		dest = mm.Destination(n.Data().Path)
+		if _, err := os.Stat(dest); err == nil {
+			return dest, false
+		}
+	}
+	if merged := mergedPath(n); merged != "" {
+		return merged, true
+	}
+	return dest, false
+}
+
+// mergedPath returns where n ends up when one of its ancestors is moved into an
+// existing directory, or "" when none is. A folder that already has its final
+// name is left alone even when another entry of the run merges into it.
+func mergedPath(n *treeview.Node[treeview.FileInfo]) string {
+	p := n.Parent()
+	if p == nil {
+		return ""
+	}
+	name := n.Name()
+	if mm := core.GetMeta(n); mm != nil && mm.NewName != "" {
+		name = mm.NewName
+	}
+	if pm := core.GetMeta(p); pm != nil && pm.MergeIntoExisting && !pm.IsVirtual && pm.NeedsRename(p.Name()) {
+		return filepath.Join(plannedPath(p), name)
+	}
+	if base := mergedPath(p); base != "" {
+		return filepath.Join(base, name)
+	}
+	return ""
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/treeview"
+)
+
+func TestParseUpgradePolicy(t *testing.T) {
+	for in, want := range map[string]UpgradePolicy{"": UpgradeOff, "off": UpgradeOff, "delete": UpgradeDelete, "suffix": UpgradeSuffix} {
+		if got, err := ParseUpgradePolicy(in); err != nil || got != want {
+			t.Errorf("ParseUpgradePolicy(%q) = (%v,%v), want (%v,<nil>)", in, got, err, want)
+		}
+	}
+	if _, err := ParseUpgradePolicy("bogus"); err == nil {
+		t.Errorf("ParseUpgradePolicy(bogus) error = nil, want error")
+	}
+}
+
+func TestMarkUpgrades(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	os.WriteFile("S01E02.720p.HDTV.mkv", []byte("small"), 0644)
+	os.WriteFile("S01E03.mkv", []byte("existing-larger"), 0644)
+
+	better := testNewFileNode("Show.S01E02.1080p.BluRay.mkv")
+	core.EnsureMeta(better).NewName = "S01E02.720p.HDTV.mkv"
+	worse := testNewFileNode("Show.S01E02.480p.mkv")
+	core.EnsureMeta(worse).NewName = "S01E02.720p.HDTV.mkv"
+	unknown := fsNodeWithSize(t, "Show.S01E03.mkv", "tiny")
+	core.EnsureMeta(unknown).NewName = "S01E03.mkv"
+	free := testNewFileNode("Show.S01E04.mkv")
+	core.EnsureMeta(free).NewName = "S01E04.mkv"
+
+	tr := testNewTree(better, worse, unknown, free)
+	MarkUpgrades(tr, UpgradeOff, false)
+	if core.GetMeta(better).Upgrade != core.UpgradeNone {
+		t.Fatalf("MarkUpgrades(off) set a decision")
+	}
+
+	MarkUpgrades(tr, UpgradeSuffix, true)
+	tests := []struct {
+		node *treeview.Node[treeview.FileInfo]
+		want core.UpgradeDecision
+	}{
+		{better, core.UpgradeReplaceExisting},
+		{worse, core.UpgradeKeepIncoming},
+		{unknown, core.UpgradeKeepIncoming}, // size tie-breaker: existing is larger
+		{free, core.UpgradeNone},
+	}
+	for _, tc := range tests {
+		mm := core.GetMeta(tc.node)
+		if mm.Upgrade != tc.want {
+			t.Errorf("MarkUpgrades(%s) = %v, want %v", tc.node.Name(), mm.Upgrade, tc.want)
+		}
+		if tc.want == core.UpgradeReplaceExisting && mm.KeepSuffix != UpgradeKeepSuffix {
+			t.Errorf("MarkUpgrades(%s) suffix = %q, want %q", tc.node.Name(), mm.KeepSuffix, UpgradeKeepSuffix)
+		}
+		if tc.want == core.UpgradeKeepIncoming && mm.KeepSuffix != "" {
+			t.Errorf("MarkUpgrades(%s) suffix = %q, want none on the incoming copy", tc.node.Name(), mm.KeepSuffix)
+		}
+		if tc.want != core.UpgradeNone && mm.UpgradeReason == "" {
+			t.Errorf("MarkUpgrades(%s) reason is empty", tc.node.Name())
+		}
+	}
+}
+
+// fsNodeWithSize writes content to name and returns a node backed by the real FileInfo.
+func fsNodeWithSize(t *testing.T, name, content string) *treeview.Node[treeview.FileInfo] {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return treeview.NewNode(name, name, treeview.FileInfo{FileInfo: info, Path: name})
+}
+
+func TestMarkUpgradesCleanExistingName(t *testing.T) {
+	tests := []struct {
+		incoming    string
+		content     string
+		compareSize bool
+		want        core.UpgradeDecision
+		wantSuffix  string
+	}{
+		{"Show.S01E01.1080p.BluRay.mkv", "tiny", false, core.UpgradeReplaceExisting, ""},   // known quality beats none
+		{"Show.S01E01.1080p.BluRay.mkv", "tiny", true, core.UpgradeDiscardIncoming, ""},    // size decides first
+		{"Show.S01E01.mkv", "tiny", false, core.UpgradeReplaceExisting, UpgradeKeepSuffix}, // inconclusive keeps both
+		{"Show.S01E01.mkv", "larger than existing", true, core.UpgradeReplaceExisting, ""},
+	}
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	for _, tc := range tests {
+		os.Chdir(t.TempDir())
+		os.WriteFile("S01E01.mkv", []byte("existing"), 0644)
+		n := fsNodeWithSize(t, tc.incoming, tc.content)
+		mm := core.EnsureMeta(n)
+		mm.NewName = "S01E01.mkv"
+		MarkUpgrades(testNewTree(n), UpgradeDelete, tc.compareSize)
+		if mm.Upgrade != tc.want || mm.KeepSuffix != tc.wantSuffix {
+			t.Errorf("MarkUpgrades(%s, size=%v) = (%v,%q), want (%v,%q)", tc.incoming, tc.compareSize, mm.Upgrade, mm.KeepSuffix, tc.want, tc.wantSuffix)
+		}
+	}
+}
+
+func TestMoviesCommandUpgradesIntoExistingFolder(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string // release file, in a folder or loose (a virtual folder)
+		want     map[string]string
+	}{
+		{
+			name:     "folder merge replaces clean-named copy",
+			incoming: "Movie.2020.1080p.BluRay/Movie.2020.1080p.BluRay.mkv",
+			want:     map[string]string{"Movie (2020).mkv": "new"},
+		},
+		{
+			name:     "folder merge of untagged copy keeps both",
+			incoming: "Movie.2020/Movie.2020.mkv",
+			want:     map[string]string{"Movie (2020).mkv": "new", "Movie (2020).old.mkv": "old"},
+		},
+		{
+			name:     "loose file replaces clean-named copy",
+			incoming: "Movie.2020.1080p.BluRay.mkv",
+			want:     map[string]string{"Movie (2020).mkv": "new"},
+		},
+		{
+			name:     "loose untagged file keeps both",
+			incoming: "Movie.2020.mkv",
+			want:     map[string]string{"Movie (2020).mkv": "new", "Movie (2020).old.mkv": "old"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tmp := t.TempDir()
+			cwd, _ := os.Getwd()
+			defer os.Chdir(cwd)
+			os.Chdir(tmp)
+			os.MkdirAll(filepath.Dir(tc.incoming), 0755)
+			os.WriteFile(tc.incoming, []byte("new"), 0644)
+			os.Mkdir("Movie (2020)", 0755)
+			os.WriteFile("Movie (2020)/Movie (2020).mkv", []byte("old"), 0644)
+
+			cfg := MoviesCommand
+			cfg.Upgrade = UpgradeDelete
+			cfg.HardDelete = true
+			indexed, err := IndexTree(cfg, ".")
+			if err != nil {
+				t.Fatalf("IndexTree() error = %v", err)
+			}
+			if rc := NewRenameModel(cfg, BuildPlan(cfg, UnwrapRoot(indexed))).RunAll(); rc.ErrorCount() != 0 {
+				t.Errorf("RunAll() errors = %d, want 0", rc.ErrorCount())
+			}
+			entries, _ := os.ReadDir("Movie (2020)")
+			if len(entries) != len(tc.want) {
+				t.Errorf("RunAll() left %d files in Movie (2020), want %d", len(entries), len(tc.want))
+			}
+			for f, content := range tc.want {
+				if data, err := os.ReadFile(filepath.Join("Movie (2020)", f)); err != nil || string(data) != content {
+					t.Errorf("RunAll() %s = %q (%v), want %q", f, data, err, content)
+				}
+			}
+			if _, err := os.Stat(tc.incoming); err == nil {
+				t.Errorf("RunAll() left %s in place", tc.incoming)
+			}
+		})
+	}
+}
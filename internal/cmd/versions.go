@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/media"
+	"github.com/Digital-Shane/treeview"
+)
+
+// MarkVersions names different-quality copies of one movie as versions instead of
+// letting them collide: two copies planned as "Movie (2020).mkv" in the same folder
+// become "Movie (2020) - 1080p.mkv" and "Movie (2020) - 2160p.mkv". The label is the
+// resolution, or every quality token when resolutions match. Companions named after
+// a copy follow it. Copies that cannot be told apart keep their colliding name.
+func MarkVersions(t *treeview.Tree[treeview.FileInfo]) {
+	groups := map[string][]*treeview.Node[treeview.FileInfo]{}
+	var order []string
+	var companions []*treeview.Node[treeview.FileInfo]
+	for ni := range t.All(context.Background()) {
+		n := ni.Node
+		mm := core.GetMeta(n)
+		if mm == nil || mm.Type != core.MediaMovieFile || mm.NewName == "" || mm.MarkedForDeletion || n.Data().IsDir() {
+			continue
+		}
+		if !media.IsVideo(n.Name()) {
+			companions = append(companions, n)
+			continue
+		}
+		dest, _ := filepath.Abs(finalPath(n))
+		if _, seen := groups[dest]; !seen {
+			order = append(order, dest)
+		}
+		groups[dest] = append(groups[dest], n)
+	}
+
+	for _, dest := range order {
+		videos := groups[dest]
+		if len(videos) < 2 {
+			continue
+		}
+		labels := versionLabels(videos)
+		if labels == nil {
+			continue
+		}
+		dir := filepath.Dir(dest)
+		for i, v := range videos {
+			vm := core.GetMeta(v)
+			ext := media.ExtractExtension(vm.NewName)
+			stem := strings.TrimSuffix(vm.NewName, ext)
+			newStem := stem + " - " + labels[i]
+			for _, c := range companions {
+				cm := core.GetMeta(c)
+				cdest, _ := filepath.Abs(finalPath(c))
+				if strings.HasPrefix(cm.NewName, stem+".") && filepath.Dir(cdest) == dir && ownsCompanion(v, c, videos) {
+					cm.NewName = newStem + strings.TrimPrefix(cm.NewName, stem)
+				}
+			}
+			vm.NewName = newStem + ext
+		}
+	}
+}
+
+// versionLabels returns one distinct label per video, or nil when the copies
+// cannot be told apart by their quality.
+func versionLabels(videos []*treeview.Node[treeview.FileInfo]) []string {
+	resolution := func(q media.Quality) string {
+		if q.Resolution == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%dp", q.Resolution)
+	}
+	for _, label := range []func(media.Quality) string{resolution, media.Quality.String} {
+		labels := make([]string, len(videos))
+		seen := map[string]bool{}
+		for i, v := range videos {
+			q := versionQuality(v)
+			l := label(q)
+			if !q.Known() || l == "" || seen[l] {
+				labels = nil
+				break
+			}
+			seen[l] = true
+			labels[i] = l
+		}
+		if labels != nil {
+			return labels
+		}
+	}
+	return nil
+}
+
+// versionQuality parses the quality of a video from its name, or from its
+// folder's name when the file name carries none.
+func versionQuality(v *treeview.Node[treeview.FileInfo]) media.Quality {
+	q := media.ParseQuality(v.Name())
+	if !q.Known() && v.Parent() != nil {
+		q = media.ParseQuality(v.Parent().Name())
+	}
+	return q
+}
+
+// ownsCompanion reports whether companion c belongs to video v among the copies
+// in videos: v has the longest base name prefixing c's name, or, when no copy's
+// name matches, v is the only copy in c's folder.
+func ownsCompanion(v, c *treeview.Node[treeview.FileInfo], videos []*treeview.Node[treeview.FileInfo]) bool {
+	name := strings.ToLower(c.Name())
+	best, bestLen := (*treeview.Node[treeview.FileInfo])(nil), 0
+	for _, o := range videos {
+		base := strings.ToLower(strings.TrimSuffix(o.Name(), media.ExtractExtension(o.Name())))
+		if strings.HasPrefix(name, base+".") && len(base) > bestLen {
+			best, bestLen = o, len(base)
+		}
+	}
+	if best != nil {
+		return best == v
+	}
+	for _, o := range videos {
+		if o != v && o.Parent() == c.Parent() {
+			return false
+		}
+	}
+	return v.Parent() == c.Parent()
+}
+
+// finalPath returns where an annotated node ends up once every renamed ancestor
+// is accounted for, unlike plannedPath which only follows virtual parents. A
+// DestDir naming one of the node's ancestors follows that ancestor's new name.
+// Top-level virtual directories yield a path relative to the working directory.
+func finalPath(n *treeview.Node[treeview.FileInfo]) string {
+	mm := core.GetMeta(n)
+	if mm == nil || mm.NewName == "" {
+		return n.Data().Path
+	}
+	for p := n.Parent(); p != nil; p = p.Parent() {
+		if mm.DestDir == "" || p.Data().Path == mm.DestDir {
+			return filepath.Join(finalPath(p), mm.NewName)
+		}
+	}
+	return mm.Destination(n.Data().Path)
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/treeview"
+)
+
+func TestMarkVersions(t *testing.T) {
+	hd := testNewFileNode("Movie.2020.1080p.BluRay.mkv")
+	uhd := testNewFileNode("Movie.2020.2160p.WEB-DL.mkv")
+	hdSub := testNewFileNode("Movie.2020.1080p.BluRay.en.srt")
+	tr := testNewTree(MoviePreprocess([]*treeview.Node[treeview.FileInfo]{hd, uhd, hdSub})...)
+	MarkVersions(tr)
+
+	for n, want := range map[*treeview.Node[treeview.FileInfo]]string{
+		hd:    "Movie (2020) - 1080p.mkv",
+		uhd:   "Movie (2020) - 2160p.mkv",
+		hdSub: "Movie (2020) - 1080p.en.srt",
+	} {
+		if got := core.GetMeta(n).NewName; got != want {
+			t.Errorf("MarkVersions(%s) NewName = %q, want %q", n.Name(), got, want)
+		}
+	}
+}
+
+func TestMarkVersions_Labels(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  []string
+	}{
+		{"same resolution uses every token", []string{"Movie.2020.1080p.BluRay.mkv", "Movie.2020.1080p.WEB-DL.mkv"},
+			[]string{"Movie (2020) - 1080p BluRay.mkv", "Movie (2020) - 1080p WEB-DL.mkv"}},
+		{"indistinguishable copies collide", []string{"Movie.2020.1080p.mkv", "Movie 2020 1080p.mkv"},
+			[]string{"Movie (2020).mkv", "Movie (2020).mkv"}},
+		{"distinct editions are not versions", []string{"Movie.2020.Extended.1080p.mkv", "Movie.2020.2160p.mkv"},
+			[]string{"Movie (2020) {edition-Extended}.mkv", "Movie (2020).mkv"}},
+	}
+	for _, tc := range tests {
+		var nodes []*treeview.Node[treeview.FileInfo]
+		for _, f := range tc.files {
+			nodes = append(nodes, testNewFileNode(f))
+		}
+		files := append([]*treeview.Node[treeview.FileInfo](nil), nodes...)
+		MarkVersions(testNewTree(MoviePreprocess(nodes)...))
+		for i, n := range files {
+			if got := core.GetMeta(n).NewName; got != tc.want[i] {
+				t.Errorf("%s: MarkVersions(%s) NewName = %q, want %q", tc.name, n.Name(), got, tc.want[i])
+			}
+		}
+	}
+}
+
+func TestMoviesCommandKeepsEditionsAndVersions(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	for _, f := range []string{
+		"Blade.Runner.1982.Final.Cut.2160p.mkv",
+		"Blade.Runner.1982.Theatrical.1080p.mkv",
+		"Blade.Runner.1982.Theatrical.720p/Blade.Runner.1982.Theatrical.720p.mkv",
+	} {
+		os.MkdirAll(filepath.Dir(f), 0755)
+		os.WriteFile(f, []byte("video"), 0644)
+	}
+
+	indexed, err := IndexTree(MoviesCommand, ".")
+	if err != nil {
+		t.Fatalf("IndexTree() error = %v", err)
+	}
+	tr := BuildPlan(MoviesCommand, UnwrapRoot(indexed))
+	if rc := NewRenameModel(MoviesCommand, tr).RunAll(); rc.ErrorCount() != 0 {
+		t.Errorf("RunAll() errors = %d, want 0", rc.ErrorCount())
+	}
+	for _, want := range []string{
+		"Blade Runner (1982)/Blade Runner (1982) {edition-Final Cut}.mkv",
+		"Blade Runner (1982)/Blade Runner (1982) {edition-Theatrical} - 1080p.mkv",
+		"Blade Runner (1982)/Blade Runner (1982) {edition-Theatrical} - 720p.mkv",
+	} {
+		if _, err := os.Stat(want); err != nil {
+			t.Errorf("RunAll() did not create %s: %v", want, err)
+		}
+	}
+}
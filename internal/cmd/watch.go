@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/media"
+	"github.com/Digital-Shane/title-tidy/internal/watch"
+)
+
+// DefaultQuietPeriod is how long new downloads must stay unchanged before the
+// watch command renames them.
+const DefaultQuietPeriod = 30 * time.Second
+
+// WatchConfig holds the watch-specific settings layered on top of a CommandConfig.
+//
+// Fields:
+//   - Dir: download directory to monitor.
+//   - Quiet: stability period before an entry is processed.
+//   - Interval: how often pending entries are checked (and the directory polled).
+//   - Poll: use polling even when inotify is available.
+type WatchConfig struct {
+	Dir      string
+	Quiet    time.Duration
+	Interval time.Duration
+	Poll     bool
+}
+
+// RunWatch monitors wc.Dir and renames new entries headless with cfg until ctx is
+// cancelled (e.g. on SIGTERM).
+func RunWatch(ctx context.Context, cfg CommandConfig, wc WatchConfig) error {
+	if err := os.Chdir(wc.Dir); err != nil {
+		return err
+	}
+	logger := log.New(os.Stderr, "title-tidy: ", log.LstdFlags)
+	return watch.Run(ctx, watch.Options{
+		Dir:      ".",
+		Quiet:    wc.Quiet,
+		Interval: wc.Interval,
+		Poll:     wc.Poll,
+		Logger:   logger,
+	}, func(names []string) ([]string, []string, error) {
+		return ProcessEntries(cfg, names, logger)
+	})
+}
+
+// ProcessEntries runs the full rename pipeline without a UI on the named
+// top-level entries of the working directory and logs the outcome. Only those
+// entries are indexed. It returns the top-level names present after the run, and
+// the loose companion files held back because their video is not in the batch
+// yet (still downloading), so they can be renamed together with it later.
+func ProcessEntries(cfg CommandConfig, names []string, logger *log.Logger) (produced, held []string, err error) {
+	var batch []string
+	for _, name := range names {
+		if video := companionVideo(name); video != "" && !slices.Contains(names, video) {
+			logger.Printf("holding %s until %s is ready", name, video)
+			held = append(held, name)
+			continue
+		}
+		batch = append(batch, name)
+	}
+	nodes, err := IndexEntries(cfg, batch)
+	if err != nil {
+		return nil, held, err
+	}
+	if len(nodes) == 0 {
+		return nil, held, nil
+	}
+
+	t := BuildPlan(cfg, nodes)
+	result := NewRenameModel(cfg, t).RunAll()
+	for ni := range t.All(context.Background()) {
+		if mm := core.GetMeta(ni.Node); mm != nil && mm.RenameStatus == core.RenameStatusError {
+			logger.Printf("error: %s: %s", ni.Node.Name(), mm.RenameError)
+		}
+	}
+	logger.Printf("batch done: %d succeeded, %d failed, %d directories cleaned",
+		result.SuccessCount(), result.ErrorCount(), result.CleanedCount())
+
+	for _, n := range t.Nodes() {
+		produced = append(produced, filepath.Base(n.Data().Path))
+	}
+	if result.ErrorCount() > 0 {
+		return produced, held, fmt.Errorf("%d errors occurred during renaming", result.ErrorCount())
+	}
+	return produced, held, nil
+}
+
+// companionVideo returns the loose video of the working directory that the
+// companion file name belongs to ("Movie.2020.mkv" for "Movie.2020.en.srt"), or
+// "" when name is not a companion or has no such video.
+func companionVideo(name string) string {
+	if !media.IsCompanion(name) {
+		return ""
+	}
+	entries, err := os.ReadDir(".")
+	if err != nil {
+		return ""
+	}
+	for _, suffix := range media.CompanionSuffixes(name) {
+		stem := name[:len(name)-len(suffix)]
+		for _, e := range entries {
+			if !e.IsDir() && media.IsVideo(e.Name()) && strings.TrimSuffix(e.Name(), media.ExtractExtension(e.Name())) == stem {
+				return e.Name()
+			}
+		}
+	}
+	return ""
+}
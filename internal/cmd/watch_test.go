@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestProcessEntries(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	os.WriteFile("Movie.2020.1080p.mkv", []byte("video"), 0644)
+	os.WriteFile("Other.Movie.2019.mkv", []byte("video"), 0644)
+
+	produced, held, err := ProcessEntries(MoviesCommand, []string{"Movie.2020.1080p.mkv"}, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatalf("ProcessEntries() error = %v", err)
+	}
+	if !slices.Equal(produced, []string{"Movie (2020)"}) || len(held) != 0 {
+		t.Errorf("ProcessEntries() = %v, held %v; want [Movie (2020)], none held", produced, held)
+	}
+	if _, err := os.Stat(filepath.Join("Movie (2020)", "Movie (2020).mkv")); err != nil {
+		t.Errorf("ProcessEntries() did not file the new movie: %v", err)
+	}
+	if _, err := os.Stat("Other.Movie.2019.mkv"); err != nil {
+		t.Errorf("ProcessEntries() touched an entry outside the batch: %v", err)
+	}
+}
+
+func TestProcessEntriesHoldsCompanionsOfPendingVideos(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	os.WriteFile("Movie.2020.1080p.mkv", []byte("video"), 0644)
+	os.WriteFile("Movie.2020.1080p.en.srt", []byte("subtitle"), 0644)
+	logger := log.New(io.Discard, "", 0)
+
+	// The subtitle finished first while the video is still downloading.
+	produced, held, err := ProcessEntries(MoviesCommand, []string{"Movie.2020.1080p.en.srt"}, logger)
+	if err != nil || len(produced) != 0 || !slices.Equal(held, []string{"Movie.2020.1080p.en.srt"}) {
+		t.Fatalf("ProcessEntries(subtitle) = %v, held %v, %v; want the subtitle held", produced, held, err)
+	}
+	if _, err := os.Stat("Movie.2020.1080p.en.srt"); err != nil {
+		t.Errorf("ProcessEntries() renamed a held subtitle: %v", err)
+	}
+
+	produced, held, err = ProcessEntries(MoviesCommand, []string{"Movie.2020.1080p.en.srt", "Movie.2020.1080p.mkv"}, logger)
+	if err != nil || !slices.Equal(produced, []string{"Movie (2020)"}) || len(held) != 0 {
+		t.Fatalf("ProcessEntries(both) = %v, held %v, %v; want [Movie (2020)]", produced, held, err)
+	}
+	for _, want := range []string{"Movie (2020).mkv", "Movie (2020).en.srt"} {
+		if _, err := os.Stat(filepath.Join("Movie (2020)", want)); err != nil {
+			t.Errorf("ProcessEntries() did not file %s with its video: %v", want, err)
+		}
+	}
+}
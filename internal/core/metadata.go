@@ -1,16 +1,25 @@
 package core
 
-import "github.com/Digital-Shane/treeview"
+import (
+	"path/filepath"
+
+	"github.com/Digital-Shane/treeview"
+)
 
 // MediaType enumerates the semantic classification of a node within the media library hierarchy.
 type MediaType int
 
 const (
-	MediaShow      MediaType = iota // Top‑level TV show directory
-	MediaSeason                     // Season directory inside a show
-	MediaEpisode                    // Individual episode file (video or subtitle)
-	MediaMovie                      // Movie directory (real or virtual)
-	MediaMovieFile                  // File inside a movie directory (video or subtitle)
+	MediaShow       MediaType = iota // Top‑level TV show directory
+	MediaSeason                      // Season directory inside a show
+	MediaEpisode                     // Individual episode file (video or subtitle)
+	MediaMovie                       // Movie directory (real or virtual)
+	MediaMovieFile                   // File inside a movie directory (video or subtitle)
+	MediaSample                      // Release sample video or sample folder, never renamed
+	MediaFlattened                   // Intermediate folder whose files move up into their season or movie; removed once empty
+	MediaDisc                        // DVD or Blu-ray structure folder (VIDEO_TS, BDMV), kept intact
+	MediaCollection                  // Collection folder grouping the movies of a franchise
+	MediaExtra                       // Trailer, featurette or other bonus video, or the folder holding them
 )
 
 // RenameStatus represents the lifecycle stage of a proposed rename operation.
@@ -24,6 +33,17 @@ const (
 	RenameStatusError                       // Rename failed; see RenameError for detail
 )
 
+// UpgradeDecision records how a rename that collides with an existing file is
+// resolved when an upgrade policy is active.
+type UpgradeDecision int
+
+const (
+	UpgradeNone            UpgradeDecision = iota // No upgrade handling; an existing destination is an error
+	UpgradeReplaceExisting                        // Incoming copy is better; the existing file is displaced
+	UpgradeDiscardIncoming                        // Existing copy is as good or better; the incoming file is displaced
+	UpgradeKeepIncoming                           // Existing copy is as good or better; the incoming file is left as it is
+)
+
 // MediaMeta holds per-node rename intent and results.
 //
 // Fields:
@@ -37,6 +57,22 @@ const (
 //   - NeedsDirectory: Signals that a directory must be created before children
 //     are renamed beneath it (typically paired with IsVirtual).
 //   - MarkedForDeletion: True when the file should be deleted during rename operation.
+//   - MergeIntoExisting: True when a directory's destination already exists (on
+//     disk or claimed by a sibling) so its children will be moved into it.
+//   - Upgrade / UpgradeReason: Collision decision for files whose destination
+//     already exists, with a short human readable comparison for display.
+//   - KeepSuffix: When non-empty the worse copy of an upgrade is kept by inserting
+//     this suffix before its extension instead of being removed.
+//   - DestDir: When non-empty the node is moved into this directory (created on
+//     demand) instead of being renamed beside its current location.
+//   - Mode: Rename command chosen for a top-level entry by auto detection
+//     ("shows", "seasons", "episodes" or "movies"); empty otherwise.
+//   - DetectedLanguage: Language code guessed from a subtitle's contents and
+//     added to NewName because the name had none; shown for confirmation.
+//   - ConvertFrom: Legacy encoding (e.g. "windows-1251") a subtitle is converted
+//     from to UTF-8 before renaming; empty when no conversion is planned.
+//   - ConvertStatus / ConvertError: Outcome of that conversion, tracked apart
+//     from the rename.
 //
 // The zero value is meaningful: it encodes an untyped, unprocessed node with no rename proposal.
 type MediaMeta struct {
@@ -47,6 +83,16 @@ type MediaMeta struct {
 	IsVirtual         bool
 	NeedsDirectory    bool
 	MarkedForDeletion bool
+	MergeIntoExisting bool
+	Upgrade           UpgradeDecision
+	UpgradeReason     string
+	KeepSuffix        string
+	DestDir           string
+	Mode              string
+	DetectedLanguage  string
+	ConvertFrom       string
+	ConvertStatus     RenameStatus
+	ConvertError      string
 }
 
 // GetMeta retrieves the existing *MediaMeta attached to n or nil when absent.
@@ -84,3 +130,19 @@ func (m *MediaMeta) Fail(err error) error {
 func (m *MediaMeta) Success() {
 	m.RenameStatus = RenameStatusSuccess
 }
+
+// NeedsRename reports whether a node currently called name has a pending rename
+// or move.
+func (m *MediaMeta) NeedsRename(name string) bool {
+	return m.NewName != "" && (m.NewName != name || m.DestDir != "")
+}
+
+// Destination returns where the node at currentPath ends up: NewName inside
+// DestDir when set, otherwise NewName beside currentPath.
+func (m *MediaMeta) Destination(currentPath string) string {
+	dir := m.DestDir
+	if dir == "" {
+		dir = filepath.Dir(currentPath)
+	}
+	return filepath.Join(dir, m.NewName)
+}
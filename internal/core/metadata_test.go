@@ -97,3 +97,21 @@ func TestMediaMeta_success(t *testing.T) {
 		t.Errorf("MediaMeta.success() mutated fields = %+v", m)
 	}
 }
+
+func TestMediaMeta_destination(t *testing.T) {
+	t.Parallel()
+	m := &MediaMeta{NewName: "Movie (2020).mkv"}
+	if got, want := m.Destination("/dl/movie.mkv"), "/dl/Movie (2020).mkv"; got != want {
+		t.Errorf("MediaMeta.Destination() = %q, want %q", got, want)
+	}
+	if !m.NeedsRename("movie.mkv") || m.NeedsRename("Movie (2020).mkv") {
+		t.Errorf("MediaMeta.NeedsRename() without DestDir mismatched")
+	}
+	m.DestDir = "/lib"
+	if got, want := m.Destination("/dl/movie.mkv"), "/lib/Movie (2020).mkv"; got != want {
+		t.Errorf("MediaMeta.Destination(DestDir) = %q, want %q", got, want)
+	}
+	if !m.NeedsRename("Movie (2020).mkv") {
+		t.Errorf("MediaMeta.NeedsRename(same name, DestDir) = false, want true")
+	}
+}
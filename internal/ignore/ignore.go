@@ -0,0 +1,246 @@
+// Package ignore implements .titletidyignore files: gitignore-style pattern
+// lists that keep matching files and folders out of the index. A file applies to
+// the directory holding it and everything below; files in deeper directories are
+// consulted after their parents, so they can re-include entries with "!".
+package ignore
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// FileName is the name of the per-directory ignore file.
+const FileName = ".titletidyignore"
+
+// Matcher answers whether paths below a root directory are ignored. Ignore files
+// are read lazily and cached, so a Matcher reflects the files as they were when
+// each directory was first consulted (Add refreshes the root file).
+type Matcher struct {
+	root  string
+	mu    sync.Mutex
+	rules map[string][]rule // keyed by slash-separated directory relative to root
+}
+
+// rule is a single parsed pattern line.
+type rule struct {
+	re       *regexp.Regexp
+	negate   bool // "!pattern" re-includes a previously ignored entry
+	dirOnly  bool // "pattern/" only matches directories
+	anchored bool // pattern contains a slash and matches from the ignore file's directory
+}
+
+// New returns a Matcher for the tree rooted at root.
+func New(root string) *Matcher {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	return &Matcher{root: root, rules: map[string][]rule{}}
+}
+
+// Match reports whether p (absolute, or relative to the working directory) is
+// ignored, either directly or because one of its parent folders below the root is.
+// The ignore files themselves are always ignored.
+func (m *Matcher) Match(p string, isDir bool) bool {
+	if filepath.Base(p) == FileName {
+		return true
+	}
+	rel, ok := m.rel(p)
+	if !ok {
+		return false
+	}
+	parts := strings.Split(rel, "/")
+	for i := range parts {
+		if m.matchOne(parts[:i+1], i < len(parts)-1 || isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add appends an anchored pattern for p to the root ignore file so it stays
+// excluded from future runs.
+func (m *Matcher) Add(p string, isDir bool) error {
+	rel, ok := m.rel(p)
+	if !ok {
+		return &os.PathError{Op: "ignore", Path: p, Err: os.ErrInvalid}
+	}
+	line := "/" + escape(rel)
+	if isDir {
+		line += "/"
+	}
+	file := filepath.Join(m.root, FileName)
+	existing, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(existing) > 0 && !bytes.HasSuffix(existing, []byte("\n")) {
+		line = "\n" + line
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	m.mu.Lock()
+	delete(m.rules, ".")
+	m.mu.Unlock()
+	return f.Close()
+}
+
+// rel returns p relative to the root with forward slashes, or false when p is
+// the root itself or lies outside it.
+func (m *Matcher) rel(p string) (string, bool) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(m.root, abs)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// matchOne applies the ignore files from the root down to the parent of the entry
+// named by parts; the last matching rule decides.
+func (m *Matcher) matchOne(parts []string, isDir bool) bool {
+	ignored := false
+	for depth := 0; depth < len(parts); depth++ {
+		dir := "."
+		if depth > 0 {
+			dir = strings.Join(parts[:depth], "/")
+		}
+		target := strings.Join(parts[depth:], "/")
+		for _, r := range m.load(dir) {
+			if r.match(target, isDir) {
+				ignored = !r.negate
+			}
+		}
+	}
+	return ignored
+}
+
+// load returns the cached rules of dir, reading its ignore file on first use.
+func (m *Matcher) load(dir string) []rule {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if rules, ok := m.rules[dir]; ok {
+		return rules
+	}
+	var rules []rule
+	if f, err := os.Open(filepath.Join(m.root, filepath.FromSlash(dir), FileName)); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if r, ok := parseRule(scanner.Text()); ok {
+				rules = append(rules, r)
+			}
+		}
+		f.Close()
+	}
+	m.rules[dir] = rules
+	return rules
+}
+
+// match reports whether the rule matches target, a path relative to the
+// directory of the ignore file the rule came from.
+func (r rule) match(target string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if !r.anchored {
+		target = path.Base(target)
+	}
+	return r.re.MatchString(target)
+}
+
+// parseRule parses one line of an ignore file. Blank lines and comments yield false.
+func parseRule(line string) (rule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule{}, false
+	}
+	var r rule
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		r.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return rule{}, false
+	}
+	re, err := regexp.Compile("^" + globToRegexp(line) + "$")
+	if err != nil {
+		return rule{}, false
+	}
+	r.re = re
+	return r, true
+}
+
+// globToRegexp translates gitignore glob syntax: "*" and "?" stay within one path
+// segment, "**" spans segments and "[...]" is a character class.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// escape quotes glob metacharacters in a literal path so Add matches it exactly.
+func escape(p string) string {
+	var b strings.Builder
+	for _, r := range p {
+		if strings.ContainsRune(`*?[\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
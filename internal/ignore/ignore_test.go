@@ -0,0 +1,85 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, FileName), []byte(`# Synology and QNAP metadata
+@eaDir/
+\#recycle/
+*.part
+/Downloads/incomplete/
+Show/**/extras
+!keep.part
+`), 0644)
+	os.MkdirAll(filepath.Join(root, "Show", "Season 1"), 0755)
+	os.WriteFile(filepath.Join(root, "Show", FileName), []byte("*.nfo\n"), 0644)
+	os.WriteFile(filepath.Join(root, "Show", "Season 1", FileName), []byte("!tvshow.nfo\n"), 0644)
+
+	m := New(root)
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"@eaDir", true, true},
+		{"Movie/@eaDir", true, true},
+		{"Movie/@eaDir/poster.jpg", false, true},
+		{"@eaDir", false, false}, // directory-only pattern
+		{"#recycle", true, true},
+		{"movie.mkv.part", false, true},
+		{"keep.part", false, false},
+		{"Downloads/incomplete", true, true},
+		{"Other/Downloads/incomplete", true, false}, // anchored to the root
+		{"Show/Season 1/extras", true, true},
+		{"Show/extras", true, true},
+		{"Show/episode.nfo", false, true},
+		{"Show/Season 1/tvshow.nfo", false, false}, // re-included by a deeper file
+		{"Movie/movie.nfo", false, false},          // Show/ rules stay below Show/
+		{"Movie/movie.mkv", false, false},
+		{FileName, false, true},
+	}
+	for _, tc := range tests {
+		if got := m.Match(filepath.Join(root, tc.path), tc.isDir); got != tc.want {
+			t.Errorf("Match(%q, dir=%v) = %v, want %v", tc.path, tc.isDir, got, tc.want)
+		}
+	}
+	if m.Match(filepath.Dir(root), true) || m.Match(root, true) {
+		t.Errorf("Match() ignored a path outside the root")
+	}
+}
+
+func TestAdd(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, FileName), []byte("*.part"), 0644)
+	m := New(root)
+	if m.Match(filepath.Join(root, "Odd [1080p]"), true) {
+		t.Fatalf("Match() before Add = true, want false")
+	}
+
+	if err := m.Add(filepath.Join(root, "Odd [1080p]"), true); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := m.Add(filepath.Join(root, "Movie", "clip.mkv"), false); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(root, FileName))
+	if want := "*.part\n/Odd \\[1080p]/\n/Movie/clip.mkv\n"; string(data) != want {
+		t.Errorf("Add() file = %q, want %q", data, want)
+	}
+	for _, p := range []string{"Odd [1080p]", "Odd [1080p]/movie.mkv", "Movie/clip.mkv"} {
+		if !m.Match(filepath.Join(root, p), p == "Odd [1080p]") {
+			t.Errorf("Match(%q) after Add = false, want true", p)
+		}
+	}
+	if m.Match(filepath.Join(root, "Odd 1"), true) {
+		t.Errorf("Add() pattern matched more than the literal name")
+	}
+	if err := m.Add(filepath.Dir(root), true); err == nil {
+		t.Errorf("Add() outside the root succeeded, want error")
+	}
+}
@@ -0,0 +1,146 @@
+// Package langdetect guesses the language of subtitle text offline. Text in a
+// distinctive script (Greek, Hebrew, Japanese...) is identified by its script;
+// Latin and Cyrillic text is compared against character trigram profiles built
+// from short samples of each supported language.
+package langdetect
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+const (
+	// minLetters is the amount of text below which Detect does not guess.
+	minLetters = 200
+	// minScore is the lowest trigram similarity accepted as a match.
+	minScore = 0.3
+	// minMargin is how far the best profile must lead the runner-up.
+	minMargin = 0.03
+)
+
+// profile is an L2-normalized trigram frequency vector.
+type profile map[string]float64
+
+// profiles holds one profile per language in samples.
+var profiles = func() map[string]profile {
+	ps := make(map[string]profile, len(samples))
+	for code, text := range samples {
+		ps[code] = newProfile(text)
+	}
+	return ps
+}()
+
+// scriptLanguages maps scripts used by a single supported language to its code.
+var scriptLanguages = []struct {
+	script *unicode.RangeTable
+	code   string
+}{
+	{unicode.Greek, "el"},
+	{unicode.Hebrew, "he"},
+	{unicode.Arabic, "ar"},
+	{unicode.Thai, "th"},
+	{unicode.Hangul, "ko"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Han, "zh"},
+}
+
+// Detect returns the ISO 639-1 code of the language text is written in and a
+// confidence between 0 and 1. It returns "" when the text is too short or no
+// language stands out.
+func Detect(text string) (string, float64) {
+	letters, kana := 0, 0
+	scripts := make([]int, len(scriptLanguages))
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.In(r, unicode.Hiragana, unicode.Katakana) {
+			kana++
+		}
+		for i, s := range scriptLanguages {
+			if unicode.Is(s.script, r) {
+				scripts[i]++
+			}
+		}
+	}
+	if letters < minLetters/4 {
+		return "", 0
+	}
+	// Japanese mixes kana with Han characters; any real share of kana settles it.
+	if kana*10 >= letters {
+		return "ja", float64(kana) / float64(letters)
+	}
+	for i, n := range scripts {
+		if n*2 > letters {
+			return scriptLanguages[i].code, float64(n) / float64(letters)
+		}
+	}
+	if letters < minLetters {
+		return "", 0
+	}
+
+	p := newProfile(text)
+	best, bestScore, second := "", 0.0, 0.0
+	for code, ref := range profiles {
+		score := p.similarity(ref)
+		switch {
+		case score > bestScore:
+			best, bestScore, second = code, score, bestScore
+		case score > second:
+			second = score
+		}
+	}
+	if bestScore < minScore || bestScore-second < minMargin {
+		return "", 0
+	}
+	return best, bestScore
+}
+
+// newProfile counts the letter trigrams of text, treating every run of
+// non-letters as a single space so word boundaries take part in the profile.
+func newProfile(text string) profile {
+	var b strings.Builder
+	b.WriteByte(' ')
+	space := true
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) {
+			b.WriteRune(r)
+			space = false
+		} else if !space {
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+	if !space {
+		b.WriteByte(' ')
+	}
+
+	runes := []rune(b.String())
+	p := profile{}
+	for i := 0; i+3 <= len(runes); i++ {
+		p[string(runes[i:i+3])]++
+	}
+	var norm float64
+	for _, n := range p {
+		norm += n * n
+	}
+	norm = math.Sqrt(norm)
+	for g := range p {
+		p[g] /= norm
+	}
+	return p
+}
+
+// similarity returns the cosine similarity of two normalized profiles.
+func (p profile) similarity(other profile) float64 {
+	if len(other) < len(p) {
+		p, other = other, p
+	}
+	var dot float64
+	for g, n := range p {
+		dot += n * other[g]
+	}
+	return dot
+}
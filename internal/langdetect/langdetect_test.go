@@ -0,0 +1,63 @@
+package langdetect
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"english", `I can't believe you're still here. The storm is getting worse and the road to the city is closed.
+			We should stay with your parents tonight. Do you remember the first time we came to this place?
+			You were so afraid of the dark that you wouldn't leave my side. Nothing has changed, has it?`, "en"},
+		{"spanish", `No puedo creer que sigas aquí. La tormenta está empeorando y la carretera a la ciudad está cerrada.
+			Deberíamos quedarnos con tus padres esta noche. ¿Te acuerdas de la primera vez que vinimos a este lugar?
+			Tenías tanto miedo de la oscuridad que no te separabas de mí. Nada ha cambiado, ¿verdad?`, "es"},
+		{"french", `Je n'arrive pas à croire que tu sois encore là. La tempête empire et la route de la ville est fermée.
+			On devrait rester chez tes parents ce soir. Tu te souviens de la première fois qu'on est venus ici ?
+			Tu avais tellement peur du noir que tu ne me quittais pas. Rien n'a changé, hein ?`, "fr"},
+		{"german", `Ich kann nicht glauben, dass du immer noch hier bist. Der Sturm wird schlimmer und die Straße in die Stadt ist gesperrt.
+			Wir sollten heute Nacht bei deinen Eltern bleiben. Erinnerst du dich an das erste Mal, als wir hierher kamen?
+			Du hattest solche Angst vor der Dunkelheit, dass du mir nicht von der Seite gewichen bist.`, "de"},
+		{"portuguese", `Não acredito que você ainda está aqui. A tempestade está piorando e a estrada para a cidade está fechada.
+			A gente devia ficar com os seus pais esta noite. Você se lembra da primeira vez que viemos a este lugar?
+			Você tinha tanto medo do escuro que não saía do meu lado. Nada mudou, não é?`, "pt"},
+		{"swedish", `Jag kan inte tro att du fortfarande är kvar. Stormen blir värre och vägen till staden är avstängd.
+			Vi borde stanna hos dina föräldrar i natt. Minns du första gången vi kom hit?
+			Du var så rädd för mörkret att du inte ville lämna min sida. Ingenting har förändrats, eller hur?`, "sv"},
+		{"polish", `Nie mogę uwierzyć, że wciąż tu jesteś. Burza się nasila, a droga do miasta jest zamknięta.
+			Powinniśmy zostać dziś na noc u twoich rodziców. Pamiętasz, jak przyjechaliśmy tu pierwszy raz?
+			Tak bardzo bałeś się ciemności, że nie odstępowałeś mnie na krok. Nic się nie zmieniło, prawda?`, "pl"},
+		{"russian", `Не могу поверить, что ты всё ещё здесь. Буря усиливается, а дорогу в город закрыли.
+			Нам лучше остаться на ночь у твоих родителей. Помнишь, как мы впервые приехали сюда?
+			Ты так боялся темноты, что не отходил от меня ни на шаг. Ничего не изменилось, правда?`, "ru"},
+		{"greek script", `Δεν μπορώ να πιστέψω ότι είσαι ακόμα εδώ. Η καταιγίδα χειροτερεύει και ο δρόμος για την πόλη είναι κλειστός.`, "el"},
+		{"japanese", `まだここにいるなんて信じられない。嵐がひどくなって、街への道が閉鎖されたんだ。今夜は君の両親の家に泊まろう。`, "ja"},
+		{"too short", "Hello there.", ""},
+	}
+	for _, tc := range tests {
+		if got, conf := Detect(tc.text); got != tc.want {
+			t.Errorf("Detect(%s) = %q (%.2f), want %q", tc.name, got, conf, tc.want)
+		}
+	}
+}
+
+func TestSubtitleText(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"srt", "\xef\xbb\xbf1\r\n00:00:01,000 --> 00:00:02,000\r\n<i>Hello</i> there.\r\n\r\n2\r\n00:00:03,000 --> 00:00:04,000\r\nGeneral Kenobi.\r\n", "Hello there.\nGeneral Kenobi.\n"},
+		{"vtt", "WEBVTT\n\n00:01.000 --> 00:02.000\nHello there.\n", "Hello there.\n"},
+		{"ass", "[Script Info]\nTitle: Test\n\n[Events]\nFormat: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\nDialogue: 0,0:00:01.00,0:00:02.00,Default,,0,0,0,,{\\an8}Hello, there.\\NGeneral Kenobi.\n", "Hello, there. General Kenobi.\n"},
+	}
+	for _, tc := range tests {
+		if got := SubtitleText([]byte(tc.in)); got != tc.want {
+			t.Errorf("SubtitleText(%s) = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package langdetect
+
+// samples holds short dialogue-like training texts, keyed by ISO 639-1 code, from
+// which the trigram profiles are built. They favour the everyday words subtitles
+// are made of rather than formal prose.
+var samples = map[string]string{
+	"en": `What are you doing here? I told you to wait in the car. I know, but I was worried about you.
+		Listen to me, we don't have much time. They will be here any minute and we need to get out of this house.
+		Where is your brother? He said he would meet us at the station, but nobody has seen him since last night.
+		I think something happened to him. Don't say that. Everything is going to be fine, I promise.
+		Come on, let's go. Have you got the keys? They were right there on the table. Thank you for coming back for me.
+		You should have called the police. I didn't want them to know what we did. It was the only thing we could do.`,
+	"es": `¿Qué estás haciendo aquí? Te dije que esperaras en el coche. Lo sé, pero estaba preocupada por ti.
+		Escúchame, no tenemos mucho tiempo. Van a llegar en cualquier momento y tenemos que salir de esta casa.
+		¿Dónde está tu hermano? Dijo que nos vería en la estación, pero nadie lo ha visto desde anoche.
+		Creo que le pasó algo. No digas eso. Todo va a salir bien, te lo prometo. Vamos, tenemos que irnos.
+		¿Tienes las llaves? Estaban ahí encima de la mesa. Gracias por volver a buscarme. Deberías haber llamado a la policía.
+		No quería que supieran lo que hicimos. Era lo único que podíamos hacer. Ella no sabe nada de esto, ¿verdad?`,
+	"fr": `Qu'est-ce que tu fais ici ? Je t'avais dit d'attendre dans la voiture. Je sais, mais je m'inquiétais pour toi.
+		Écoute-moi, nous n'avons pas beaucoup de temps. Ils vont arriver d'une minute à l'autre et il faut quitter cette maison.
+		Où est ton frère ? Il a dit qu'il nous retrouverait à la gare, mais personne ne l'a vu depuis hier soir.
+		Je crois qu'il lui est arrivé quelque chose. Ne dis pas ça. Tout va bien se passer, je te le promets.
+		Allez, on y va. Tu as les clés ? Elles étaient juste là, sur la table. Merci d'être revenu me chercher.
+		Tu aurais dû appeler la police. Je ne voulais pas qu'ils sachent ce que nous avons fait. C'était la seule chose à faire.`,
+	"de": `Was machst du denn hier? Ich habe dir gesagt, du sollst im Auto warten. Ich weiß, aber ich habe mir Sorgen um dich gemacht.
+		Hör mir zu, wir haben nicht viel Zeit. Sie werden jeden Moment hier sein und wir müssen aus diesem Haus raus.
+		Wo ist dein Bruder? Er hat gesagt, er trifft uns am Bahnhof, aber niemand hat ihn seit gestern Abend gesehen.
+		Ich glaube, ihm ist etwas passiert. Sag so etwas nicht. Es wird alles gut, das verspreche ich dir.
+		Komm schon, lass uns gehen. Hast du die Schlüssel? Die lagen doch gerade noch auf dem Tisch. Danke, dass du zurückgekommen bist.
+		Du hättest die Polizei rufen sollen. Ich wollte nicht, dass sie wissen, was wir getan haben. Es war das Einzige, was wir tun konnten.`,
+	"it": `Che cosa ci fai qui? Ti avevo detto di aspettare in macchina. Lo so, ma ero preoccupata per te.
+		Ascoltami, non abbiamo molto tempo. Arriveranno da un momento all'altro e dobbiamo uscire da questa casa.
+		Dov'è tuo fratello? Ha detto che ci avrebbe raggiunti alla stazione, ma nessuno lo ha visto da ieri sera.
+		Credo che gli sia successo qualcosa. Non dire così. Andrà tutto bene, te lo prometto. Dai, andiamo.
+		Hai le chiavi? Erano proprio lì sul tavolo. Grazie per essere tornato a prendermi. Avresti dovuto chiamare la polizia.
+		Non volevo che sapessero quello che abbiamo fatto. Era l'unica cosa che potevamo fare. Lei non sa niente di questo, vero?`,
+	"pt": `O que você está fazendo aqui? Eu disse para esperar no carro. Eu sei, mas estava preocupada com você.
+		Escute, não temos muito tempo. Eles vão chegar a qualquer momento e precisamos sair desta casa.
+		Onde está o seu irmão? Ele disse que nos encontraria na estação, mas ninguém o viu desde ontem à noite.
+		Acho que aconteceu alguma coisa com ele. Não diga isso. Vai ficar tudo bem, eu prometo. Vamos, temos que ir.
+		Você está com as chaves? Elas estavam ali em cima da mesa. Obrigado por ter voltado para me buscar.
+		Você devia ter chamado a polícia. Eu não queria que eles soubessem o que fizemos. Era a única coisa que podíamos fazer. Não é?`,
+	"nl": `Wat doe jij hier? Ik zei toch dat je in de auto moest wachten. Ik weet het, maar ik maakte me zorgen om je.
+		Luister naar me, we hebben niet veel tijd. Ze kunnen elk moment hier zijn en we moeten uit dit huis weg.
+		Waar is je broer? Hij zei dat hij ons bij het station zou ontmoeten, maar niemand heeft hem sinds gisteravond gezien.
+		Ik denk dat er iets met hem is gebeurd. Zeg dat niet. Het komt allemaal goed, dat beloof ik je. Kom op, we gaan.
+		Heb jij de sleutels? Ze lagen net nog op de tafel. Bedankt dat je voor me terugkwam. Je had de politie moeten bellen.
+		Ik wilde niet dat ze wisten wat we gedaan hebben. Het was het enige wat we konden doen. Zij weet hier niets van, toch?`,
+	"sv": `Vad gör du här? Jag sa ju att du skulle vänta i bilen. Jag vet, men jag var orolig för dig.
+		Lyssna på mig, vi har inte mycket tid. De kan vara här när som helst och vi måste ut ur det här huset.
+		Var är din bror? Han sa att han skulle möta oss vid stationen, men ingen har sett honom sedan i går kväll.
+		Jag tror att något har hänt honom. Säg inte så. Det kommer att ordna sig, det lovar jag. Kom nu, vi går.
+		Har du nycklarna? De låg ju precis där på bordet. Tack för att du kom tillbaka och hämtade mig.
+		Du borde ha ringt polisen. Jag ville inte att de skulle veta vad vi gjorde. Det var det enda vi kunde göra. Hon vet ingenting om det här, eller hur?`,
+	"da": `Hvad laver du her? Jeg sagde jo, at du skulle vente i bilen. Det ved jeg godt, men jeg var bekymret for dig.
+		Hør her, vi har ikke meget tid. De kan være her hvert øjeblik, og vi er nødt til at komme ud af det her hus.
+		Hvor er din bror? Han sagde, at han ville møde os ved stationen, men ingen har set ham siden i går aftes.
+		Jeg tror, der er sket ham noget. Sig ikke det. Det skal nok gå, det lover jeg dig. Kom nu, lad os gå.
+		Har du nøglerne? De lå lige der på bordet. Tak fordi du kom tilbage efter mig. Du burde have ringet til politiet.
+		Jeg ville ikke have, at de skulle vide, hvad vi havde gjort. Det var det eneste, vi kunne gøre. Hun ved ikke noget om det, vel?`,
+	"no": `Hva gjør du her? Jeg sa jo at du skulle vente i bilen. Jeg vet det, men jeg var bekymret for deg.
+		Hør på meg, vi har ikke mye tid. De kan være her når som helst, og vi må komme oss ut av dette huset.
+		Hvor er broren din? Han sa at han skulle møte oss på stasjonen, men ingen har sett ham siden i går kveld.
+		Jeg tror det har skjedd ham noe. Ikke si det. Dette kommer til å gå bra, det lover jeg deg. Kom igjen, vi drar.
+		Har du nøklene? De lå jo akkurat der på bordet. Takk for at du kom tilbake for å hente meg. Du skulle ha ringt politiet.
+		Jeg ville ikke at de skulle vite hva vi hadde gjort. Det var det eneste vi kunne gjøre. Hun vet ikke noe om dette, gjør hun vel?`,
+	"fi": `Mitä sinä täällä teet? Sanoin, että sinun pitää odottaa autossa. Tiedän, mutta olin huolissani sinusta.
+		Kuuntele minua, meillä ei ole paljon aikaa. He voivat tulla tänne minä hetkenä hyvänsä, ja meidän täytyy päästä pois tästä talosta.
+		Missä veljesi on? Hän sanoi tapaavansa meidät asemalla, mutta kukaan ei ole nähnyt häntä eilisillan jälkeen.
+		Luulen, että hänelle on tapahtunut jotain. Älä sano noin. Kaikki järjestyy, minä lupaan sen. Tule, mennään.
+		Onko sinulla avaimet? Ne olivat juuri tuossa pöydällä. Kiitos, että tulit takaisin hakemaan minut. Sinun olisi pitänyt soittaa poliisille.
+		En halunnut heidän tietävän, mitä me teimme. Se oli ainoa asia, jonka pystyimme tekemään. Hän ei tiedä tästä mitään, eihän?`,
+	"pl": `Co ty tutaj robisz? Mówiłem ci, żebyś czekała w samochodzie. Wiem, ale martwiłam się o ciebie.
+		Posłuchaj mnie, nie mamy dużo czasu. Będą tu lada chwila i musimy wydostać się z tego domu.
+		Gdzie jest twój brat? Powiedział, że spotka się z nami na stacji, ale nikt go nie widział od wczoraj wieczorem.
+		Myślę, że coś mu się stało. Nie mów tak. Wszystko będzie dobrze, obiecuję ci. Chodź, musimy iść.
+		Masz klucze? Leżały przecież tutaj na stole. Dziękuję, że po mnie wróciłeś. Powinieneś był zadzwonić na policję.
+		Nie chciałem, żeby wiedzieli, co zrobiliśmy. To była jedyna rzecz, jaką mogliśmy zrobić. Ona nic o tym nie wie, prawda?`,
+	"cs": `Co tady děláš? Říkal jsem ti, ať počkáš v autě. Já vím, ale měla jsem o tebe strach.
+		Poslouchej mě, nemáme moc času. Budou tady každou chvíli a musíme se dostat z tohohle domu.
+		Kde je tvůj bratr? Říkal, že se s námi sejde na nádraží, ale od včerejšího večera ho nikdo neviděl.
+		Myslím, že se mu něco stalo. Neříkej to. Všechno bude v pořádku, slibuju. No tak, musíme jít.
+		Máš ty klíče? Ležely přece tady na stole. Děkuju, že ses pro mě vrátil. Měl jsi zavolat policii.
+		Nechtěl jsem, aby věděli, co jsme udělali. Byla to jediná věc, kterou jsme mohli udělat. Ona o tom nic neví, že ne?`,
+	"hu": `Mit csinálsz itt? Mondtam, hogy várj a kocsiban. Tudom, de aggódtam érted.
+		Figyelj rám, nincs sok időnk. Bármelyik percben ideérhetnek, és ki kell jutnunk ebből a házból.
+		Hol van a bátyád? Azt mondta, hogy az állomáson találkozunk, de tegnap este óta senki sem látta.
+		Azt hiszem, valami történt vele. Ne mondj ilyet. Minden rendben lesz, megígérem. Gyere, menjünk.
+		Nálad vannak a kulcsok? Pont ott voltak az asztalon. Köszönöm, hogy visszajöttél értem. Fel kellett volna hívnod a rendőrséget.
+		Nem akartam, hogy megtudják, mit csináltunk. Ez volt az egyetlen dolog, amit tehettünk. Ő nem tud erről semmit, ugye?`,
+	"tr": `Burada ne yapıyorsun? Sana arabada beklemeni söylemiştim. Biliyorum ama senin için endişelendim.
+		Beni dinle, fazla zamanımız yok. Her an burada olabilirler ve bu evden çıkmamız gerekiyor.
+		Kardeşin nerede? Bizimle istasyonda buluşacağını söyledi ama dün geceden beri kimse onu görmedi.
+		Sanırım ona bir şey oldu. Öyle söyleme. Her şey yoluna girecek, sana söz veriyorum. Hadi, gidelim.
+		Anahtarlar sende mi? Az önce şu masanın üstündeydiler. Beni almak için geri döndüğün için teşekkür ederim.
+		Polisi araman gerekirdi. Ne yaptığımızı bilmelerini istemedim. Yapabileceğimiz tek şey buydu. Onun bundan haberi yok, değil mi?`,
+	"ro": `Ce faci aici? Ți-am spus să aștepți în mașină. Știu, dar mi-a fost teamă pentru tine.
+		Ascultă-mă, nu avem prea mult timp. Vor ajunge aici în orice moment și trebuie să ieșim din casa asta.
+		Unde este fratele tău? A spus că ne întâlnește la gară, dar nimeni nu l-a mai văzut de aseară.
+		Cred că i s-a întâmplat ceva. Nu spune asta. Totul o să fie bine, îți promit. Haide, să mergem.
+		Ai cheile? Erau chiar acolo pe masă. Mulțumesc că te-ai întors după mine. Ar fi trebuit să chemi poliția.
+		Nu voiam să afle ce am făcut. Era singurul lucru pe care îl puteam face. Ea nu știe nimic despre asta, nu-i așa?`,
+	"ru": `Что ты здесь делаешь? Я же сказал тебе ждать в машине. Я знаю, но я волновалась за тебя.
+		Послушай меня, у нас мало времени. Они будут здесь с минуты на минуту, и нам нужно выбраться из этого дома.
+		Где твой брат? Он сказал, что встретит нас на вокзале, но никто не видел его со вчерашнего вечера.
+		Мне кажется, с ним что-то случилось. Не говори так. Всё будет хорошо, я тебе обещаю. Давай, пойдём.
+		Ключи у тебя? Они только что лежали вот здесь на столе. Спасибо, что вернулся за мной. Надо было вызвать полицию.
+		Я не хотел, чтобы они узнали, что мы сделали. Это было единственное, что мы могли сделать. Она ведь ничего об этом не знает?`,
+	"uk": `Що ти тут робиш? Я ж казав тобі чекати в машині. Я знаю, але я хвилювалася за тебе.
+		Послухай мене, у нас мало часу. Вони будуть тут будь-якої хвилини, і нам треба вибратися з цього будинку.
+		Де твій брат? Він сказав, що зустріне нас на вокзалі, але ніхто не бачив його з учорашнього вечора.
+		Мені здається, з ним щось сталося. Не кажи так. Усе буде добре, я тобі обіцяю. Ходімо, нам треба йти.
+		Ключі в тебе? Вони щойно лежали ось тут на столі. Дякую, що повернувся по мене. Треба було викликати поліцію.
+		Я не хотів, щоб вони дізналися, що ми зробили. Це було єдине, що ми могли зробити. Вона ж нічого про це не знає, правда?`,
+}
@@ -0,0 +1,50 @@
+package langdetect
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// markupRe matches HTML-like tags and ASS override blocks: <i>, </font>, {\an8}.
+	markupRe = regexp.MustCompile(`<[^>]*>|\{[^}]*\}`)
+
+	// cueTimingRe matches SRT/VTT cue numbers and timing lines.
+	cueTimingRe = regexp.MustCompile(`^(?:\d+|.*-->.*)$`)
+)
+
+// SubtitleText extracts the spoken text of an SRT, VTT or ASS/SSA subtitle,
+// dropping cue numbers, timings, styles and markup. Bytes that are not valid
+// UTF-8 are discarded.
+func SubtitleText(data []byte) string {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		data = bytes.ToValidUTF8(data, nil)
+	}
+	ass := bytes.Contains(data, []byte("[Script Info]")) || bytes.Contains(data, []byte("\nDialogue:"))
+
+	var b strings.Builder
+	for line := range strings.Lines(string(data)) {
+		line = strings.TrimSpace(line)
+		switch {
+		case ass:
+			text, ok := strings.CutPrefix(line, "Dialogue:")
+			if !ok {
+				continue
+			}
+			// The text is the tenth field; earlier fields hold timings and styles.
+			fields := strings.SplitN(text, ",", 10)
+			if len(fields) < 10 {
+				continue
+			}
+			line = strings.NewReplacer(`\N`, " ", `\n`, " ", `\h`, " ").Replace(fields[9])
+		case line == "", line == "WEBVTT", cueTimingRe.MatchString(line):
+			continue
+		}
+		b.WriteString(markupRe.ReplaceAllString(line, ""))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,72 @@
+package media
+
+import (
+	"regexp"
+	"strings"
+)
+
+// qualifierRe matches a single dot-separated token that may sit between a video's
+// base name and a companion file's extension: a language code (en, eng, pt-BR) or a
+// descriptive word such as commentary or chapters.
+var qualifierRe = regexp.MustCompile(`(?i)^(?:[a-z]{2,3}(?:[-_][a-z]{2,4})?|commentary|chapters|descriptive|dub|dubbed|original|forced|sdh)$`)
+
+// maxQualifiers bounds how many qualifier tokens a companion suffix may carry,
+// e.g. "Movie.2020.en.commentary.mka".
+const maxQualifiers = 2
+
+// IsCompanion reports whether filename is a file that follows a video around:
+// subtitles, external audio tracks and other sidecars (chapters, checksums).
+func IsCompanion(filename string) bool {
+	switch CategoryOf(filename) {
+	case CategorySubtitle, CategoryAudio, CategorySidecar:
+		return true
+	}
+	return false
+}
+
+// CompanionSuffixes lists the possible suffixes of a companion file, shortest first:
+// the extension alone, then the extension with each additional qualifier. Callers
+// pick the first one whose remaining base name matches a video. Non-companion files
+// yield nil.
+//
+// "Movie.2020.en.ac3" yields [".ac3", ".en.ac3"].
+func CompanionSuffixes(filename string) []string {
+	if !IsCompanion(filename) {
+		return nil
+	}
+	if IsSubtitle(filename) {
+		_, cuts := parseSubtitleTokens(filename)
+		suffixes := make([]string, len(cuts))
+		for i, cut := range cuts {
+			suffixes[i] = filename[cut:]
+		}
+		return suffixes
+	}
+	ext := ExtractExtension(filename)
+	suffixes := []string{ext}
+	rest := filename[:len(filename)-len(ext)]
+	for range maxQualifiers {
+		dot := strings.LastIndex(rest, ".")
+		if dot <= 0 || !qualifierRe.MatchString(rest[dot+1:]) {
+			break
+		}
+		rest = rest[:dot]
+		suffixes = append(suffixes, filename[len(rest):])
+	}
+	return suffixes
+}
+
+// ExtractCompanionSuffix returns the suffix a companion file keeps when renamed
+// alongside its video. Subtitles keep their normalized language and flags (see ExtractSubtitleSuffix);
+// audio tracks and sidecars keep every recognized qualifier, so
+// "Movie.2020.commentary.mka" keeps ".commentary.mka". Non-companion files yield "".
+func ExtractCompanionSuffix(filename string) string {
+	if IsSubtitle(filename) {
+		return ExtractSubtitleSuffix(filename)
+	}
+	suffixes := CompanionSuffixes(filename)
+	if len(suffixes) == 0 {
+		return ""
+	}
+	return suffixes[len(suffixes)-1]
+}
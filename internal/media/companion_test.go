@@ -0,0 +1,44 @@
+package media
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCompanionSuffixes(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Movie.2020.en.ac3", []string{".ac3", ".en.ac3"}},
+		{"Movie.2020.commentary.mka", []string{".mka", ".commentary.mka"}},
+		{"Movie.2020.en.commentary.mka", []string{".mka", ".commentary.mka", ".en.commentary.mka"}},
+		{"Episode.S01E01.chapters.xml", []string{".xml", ".chapters.xml"}},
+		{"Movie.2020.idx", []string{".idx"}},
+		{"Movie.2020.mkv", nil},
+		{"notes.txt", nil},
+	}
+	for _, tc := range tests {
+		if got := CompanionSuffixes(tc.in); !slices.Equal(got, tc.want) {
+			t.Errorf("CompanionSuffixes(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestExtractCompanionSuffix(t *testing.T) {
+	t.Parallel()
+	tests := []struct{ in, want string }{
+		{"Movie.2020.en.ac3", ".en.ac3"},
+		{"Movie.2020.commentary.mka", ".commentary.mka"},
+		{"Episode.S01E01.chapters.xml", ".chapters.xml"},
+		{"movie.en.srt", ".en.srt"},
+		{"Movie.2020.dts", ".dts"},
+		{"movie.mkv", ""},
+	}
+	for _, tc := range tests {
+		if got := ExtractCompanionSuffix(tc.in); got != tc.want {
+			t.Errorf("ExtractCompanionSuffix(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package media
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// EditionStyle selects how a movie edition is written in new file names.
+type EditionStyle int
+
+const (
+	EditionPlex     EditionStyle = iota // "Movie (1982) {edition-Final Cut}"
+	EditionJellyfin                     // "Movie (1982) - Final Cut"
+	EditionOff                          // Editions are dropped like other release tags
+)
+
+// edition pairs a canonical edition name with the pattern recognizing it.
+type edition struct {
+	name string
+	re   *regexp.Regexp
+}
+
+var (
+	// editions lists the recognized editions; the earliest match in a name wins.
+	editions = []edition{
+		{"Final Cut", tokenRe(`final[\s._\-]*cut`)},
+		{"Director's Cut", tokenRe(`director'?s?[\s._\-]*cut`)},
+		{"Extended", tokenRe(`extended(?:[\s._\-]*(?:cut|edition))?`)},
+		{"Theatrical", tokenRe(`theatrical(?:[\s._\-]*(?:cut|edition|version))?`)},
+		{"Unrated", tokenRe(`unrated`)},
+		{"Uncut", tokenRe(`uncut`)},
+		{"Remastered", tokenRe(`remastered`)},
+		{"Special Edition", tokenRe(`special[\s._\-]*edition`)},
+		{"Ultimate Edition", tokenRe(`ultimate[\s._\-]*edition`)},
+		{"Collector's Edition", tokenRe(`collector'?s?[\s._\-]*edition`)},
+		{"IMAX", tokenRe(`imax(?:[\s._\-]*edition)?`)},
+	}
+
+	// plexEditionRe matches an edition already written the Plex way.
+	plexEditionRe = regexp.MustCompile(`\{edition-([^}]+)\}`)
+)
+
+// editionStyle is the active output style, set once at startup by SetEditionStyle.
+var editionStyle = EditionPlex
+
+// SetEditionStyle selects the style FormatEdition writes. It is not safe to call
+// while names are being formatted.
+func SetEditionStyle(s EditionStyle) { editionStyle = s }
+
+// ParseEditionStyle resolves the --edition values plex, jellyfin and off.
+func ParseEditionStyle(s string) (EditionStyle, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "plex":
+		return EditionPlex, nil
+	case "jellyfin":
+		return EditionJellyfin, nil
+	case "off", "none":
+		return EditionOff, nil
+	}
+	return EditionPlex, fmt.Errorf("unknown edition style %q (want plex, jellyfin or off)", s)
+}
+
+// ExtractEdition returns the canonical edition named in a release name, such as
+// "Final Cut" for "Blade.Runner.1982.Final.Cut.2160p", or "" when there is none.
+// Only the part after the year is searched so titles like "Director's Cut (2010)"
+// keep their name. An existing "{edition-...}" tag is returned as written.
+func ExtractEdition(name string) string {
+	if m := plexEditionRe.FindStringSubmatch(name); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+	if m, ok := findYear(name); ok {
+		name = name[m.end:]
+	}
+	best, bestAt := "", -1
+	for _, e := range editions {
+		if loc := e.re.FindStringIndex(name); loc != nil && (bestAt < 0 || loc[0] < bestAt) {
+			best, bestAt = e.name, loc[0]
+		}
+	}
+	return best
+}
+
+// FormatEdition returns the suffix added to a movie file name for edition in the
+// active EditionStyle: " {edition-Final Cut}" or " - Final Cut". It is empty when
+// edition is empty or editions are turned off.
+func FormatEdition(edition string) string {
+	if edition == "" {
+		return ""
+	}
+	switch editionStyle {
+	case EditionPlex:
+		return " {edition-" + edition + "}"
+	case EditionJellyfin:
+		return " - " + edition
+	}
+	return ""
+}
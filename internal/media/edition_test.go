@@ -0,0 +1,61 @@
+package media
+
+import "testing"
+
+func TestExtractEdition(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Blade.Runner.1982.Final.Cut.2160p", "Final Cut"},
+		{"Blade Runner 1982 Theatrical 1080p", "Theatrical"},
+		{"Aliens.1986.DiRECTORS.CUT.1080p.BluRay", "Director's Cut"},
+		{"Movie (2001) [Extended Edition]", "Extended"},
+		{"Movie.2001.EXTENDED.REMASTERED.720p", "Extended"},
+		{"Movie.2019.IMAX.2160p", "IMAX"},
+		{"Movie (2010) {edition-Anniversary Cut}", "Anniversary Cut"},
+		{"Movie (2010) - Collector's Edition", "Collector's Edition"},
+		{"Directors Cut 2010 1080p", ""},
+		{"Movie.2010.1080p.BluRay", ""},
+		{"Uncutgems.2019", ""},
+		{"2001.Final.Cut.1968.720p", ""},
+	}
+	for _, tc := range tests {
+		if got := ExtractEdition(tc.in); got != tc.want {
+			t.Errorf("ExtractEdition(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestFormatEdition(t *testing.T) {
+	defer SetEditionStyle(EditionPlex)
+	tests := []struct {
+		style EditionStyle
+		in    string
+		want  string
+	}{
+		{EditionPlex, "Final Cut", " {edition-Final Cut}"},
+		{EditionJellyfin, "Final Cut", " - Final Cut"},
+		{EditionOff, "Final Cut", ""},
+		{EditionPlex, "", ""},
+	}
+	for _, tc := range tests {
+		SetEditionStyle(tc.style)
+		if got := FormatEdition(tc.in); got != tc.want {
+			t.Errorf("FormatEdition(%q) with style %d = %q, want %q", tc.in, tc.style, got, tc.want)
+		}
+	}
+}
+
+func TestParseEditionStyle(t *testing.T) {
+	t.Parallel()
+	for in, want := range map[string]EditionStyle{"": EditionPlex, "Plex": EditionPlex, "jellyfin": EditionJellyfin, "off": EditionOff} {
+		if got, err := ParseEditionStyle(in); err != nil || got != want {
+			t.Errorf("ParseEditionStyle(%q) = (%d, %v), want %d", in, got, err, want)
+		}
+	}
+	if _, err := ParseEditionStyle("kodi"); err == nil {
+		t.Error("ParseEditionStyle(\"kodi\") error = nil, want error")
+	}
+}
@@ -0,0 +1,122 @@
+package media
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Category classifies a file by its extension.
+type Category int
+
+const (
+	CategoryNone     Category = iota // Unrecognized extension
+	CategoryVideo                    // Main video files
+	CategorySubtitle                 // Subtitle tracks
+	CategoryAudio                    // External audio tracks (dubs, commentaries)
+	CategorySidecar                  // Other files that belong to a video (chapters, checksums)
+	CategoryNFO                      // Kodi / Jellyfin info files
+	CategoryImage                    // Posters, fanart and screenshots
+)
+
+// categoryNames maps the names accepted by ParseCategory and printed by String.
+var categoryNames = map[Category]string{
+	CategoryVideo:    "video",
+	CategorySubtitle: "subtitle",
+	CategoryAudio:    "audio",
+	CategorySidecar:  "sidecar",
+	CategoryNFO:      "nfo",
+	CategoryImage:    "image",
+}
+
+// defaultExtensions is the built-in registry contents, without leading dots.
+var defaultExtensions = map[Category][]string{
+	CategoryVideo:    {"mp4", "mkv", "avi", "mov", "wmv", "flv", "webm", "mpeg", "mpg", "m4v", "3gp", "vob", "ts", "mts", "m2ts", "rmvb", "divx", "ogm", "m2v", "iso"},
+	CategorySubtitle: {"srt", "sub", "idx", "ass", "ssa", "smi", "vtt", "sbv", "sami", "usf", "stl", "dks", "pjs", "jss", "psb", "rt", "scc", "cap", "sup", "dfxp", "ttml"},
+	CategoryAudio:    {"mka", "ac3", "eac3", "dts", "aac", "flac"},
+	CategorySidecar:  {"xml", "sfv"},
+	CategoryNFO:      {"nfo"},
+	CategoryImage:    {"jpg", "jpeg", "png", "gif", "bmp", "webp", "tif", "tiff", "ico", "svg"},
+}
+
+// extensions is the active registry, keyed by lower-case extension without the dot.
+// It is filled with the defaults and adjusted once at startup by RegisterExtensions.
+var extensions = map[string]Category{}
+
+func init() {
+	ResetExtensions()
+}
+
+// String returns the registry name of c.
+func (c Category) String() string {
+	if name, ok := categoryNames[c]; ok {
+		return name
+	}
+	return "none"
+}
+
+// ParseCategory resolves a category name such as "video" or "audio".
+func ParseCategory(name string) (Category, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for c, n := range categoryNames {
+		if n == name || n+"s" == name {
+			return c, nil
+		}
+	}
+	return CategoryNone, fmt.Errorf("unknown file category %q (want video, subtitle, audio, sidecar, nfo or image)", name)
+}
+
+// CategoryOf classifies filename by its extension.
+func CategoryOf(filename string) Category {
+	ext := ExtractExtension(filename)
+	if ext == "" {
+		return CategoryNone
+	}
+	return extensions[strings.ToLower(ext[1:])]
+}
+
+// RegisterExtensions assigns exts (with or without a leading dot) to c, moving them
+// out of any category they belonged to. CategoryNone removes them from the registry.
+// It is not safe to call while files are being classified.
+func RegisterExtensions(c Category, exts ...string) {
+	for _, ext := range exts {
+		ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+		switch {
+		case ext == "":
+		case c == CategoryNone:
+			delete(extensions, ext)
+		default:
+			extensions[ext] = c
+		}
+	}
+}
+
+// ResetExtensions restores the built-in registry.
+func ResetExtensions() {
+	extensions = map[string]Category{}
+	for c, exts := range defaultExtensions {
+		RegisterExtensions(c, exts...)
+	}
+}
+
+// ApplyExtensionSpec updates the registry from a spec such as "video=f4v,mxf" or
+// "none=ts". Several specs may be joined with ";", as in $TITLE_TIDY_EXTENSIONS.
+func ApplyExtensionSpec(spec string) error {
+	for _, part := range strings.Split(spec, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		name, list, ok := strings.Cut(part, "=")
+		if !ok {
+			return fmt.Errorf("invalid extension spec %q (want category=ext,ext)", part)
+		}
+		c := CategoryNone
+		if n := strings.ToLower(strings.TrimSpace(name)); n != "none" {
+			var err error
+			if c, err = ParseCategory(n); err != nil {
+				return err
+			}
+		}
+		RegisterExtensions(c, strings.Split(list, ",")...)
+	}
+	return nil
+}
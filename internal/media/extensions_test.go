@@ -0,0 +1,56 @@
+package media
+
+import "testing"
+
+func TestCategoryOf(t *testing.T) {
+	tests := []struct {
+		name string
+		want Category
+	}{
+		{"movie.MKV", CategoryVideo},
+		{"movie.en.srt", CategorySubtitle},
+		{"movie.commentary.mka", CategoryAudio},
+		{"old.ogm", CategoryVideo},
+		{"stream.m2v", CategoryVideo},
+		{"episode.chapters.xml", CategorySidecar},
+		{"release.sfv", CategorySidecar},
+		{"movie.nfo", CategoryNFO},
+		{"poster.TIFF", CategoryImage},
+		{"readme.txt", CategoryNone},
+		{"noext", CategoryNone},
+	}
+	for _, tc := range tests {
+		if got := CategoryOf(tc.name); got != tc.want {
+			t.Errorf("CategoryOf(%q) = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestApplyExtensionSpec(t *testing.T) {
+	defer ResetExtensions()
+
+	if err := ApplyExtensionSpec("video=.f4v,MXF, m2v; sidecar=md5;none=ts"); err != nil {
+		t.Fatalf("ApplyExtensionSpec() error = %v", err)
+	}
+	for name, want := range map[string]Category{
+		"clip.f4v":     CategoryVideo,
+		"master.mxf":   CategoryVideo,
+		"stream.m2v":   CategoryVideo,
+		"release.md5":  CategorySidecar,
+		"broadcast.ts": CategoryNone,
+		"movie.mkv":    CategoryVideo,
+	} {
+		if got := CategoryOf(name); got != want {
+			t.Errorf("CategoryOf(%q) after spec = %v, want %v", name, got, want)
+		}
+	}
+	if !IsVideo("clip.f4v") || IsVideo("broadcast.ts") {
+		t.Errorf("IsVideo() does not follow the registry")
+	}
+
+	for _, spec := range []string{"video", "music=flac"} {
+		if err := ApplyExtensionSpec(spec); err == nil {
+			t.Errorf("ApplyExtensionSpec(%q) error = nil, want error", spec)
+		}
+	}
+}
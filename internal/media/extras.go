@@ -0,0 +1,90 @@
+package media
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ExtraKind classifies bonus material shipped with a movie or show.
+type ExtraKind int
+
+const (
+	ExtraNone            ExtraKind = iota // Not an extra, or a generic Extras/ folder
+	ExtraTrailer                          // Trailers and teasers
+	ExtraFeaturette                       // Featurettes and making-of documentaries
+	ExtraBehindTheScenes                  // Behind the scenes footage
+	ExtraDeletedScene                     // Deleted scenes
+)
+
+// extraKind describes how an ExtraKind is recognized and where it is filed.
+type extraKind struct {
+	kind   ExtraKind
+	folder string // Plex / Jellyfin extras folder
+	suffix string // Plex / Jellyfin extras file suffix
+	re     *regexp.Regexp
+}
+
+var (
+	extraKinds = []extraKind{
+		{ExtraTrailer, "Trailers", "-trailer", tokenRe(`(?:trailers?|teasers?)`)},
+		{ExtraFeaturette, "Featurettes", "-featurette", tokenRe(`(?:featurettes?|making[\s._\-]*of)`)},
+		{ExtraBehindTheScenes, "Behind The Scenes", "-behindthescenes", tokenRe(`behind[\s._\-]*the[\s._\-]*scenes`)},
+		{ExtraDeletedScene, "Deleted Scenes", "-deleted", tokenRe(`deleted(?:[\s._\-]*scenes?)?`)},
+	}
+
+	// genericExtrasDirRe matches folders of mixed bonus material: Extras, Bonus, Special Features.
+	genericExtrasDirRe = regexp.MustCompile(`(?i)^(?:extras|bonus(?:[\s._\-]*features)?|special[\s._\-]*features)$`)
+)
+
+// lookupExtra returns the description of kind, or nil for ExtraNone.
+func lookupExtra(kind ExtraKind) *extraKind {
+	for i := range extraKinds {
+		if extraKinds[i].kind == kind {
+			return &extraKinds[i]
+		}
+	}
+	return nil
+}
+
+// Folder returns the extras folder of the kind ("Trailers"), or "".
+func (k ExtraKind) Folder() string {
+	if e := lookupExtra(k); e != nil {
+		return e.folder
+	}
+	return ""
+}
+
+// Suffix returns the extras file suffix of the kind ("-trailer"), or "".
+func (k ExtraKind) Suffix() string {
+	if e := lookupExtra(k); e != nil {
+		return e.suffix
+	}
+	return ""
+}
+
+// ExtraKindOf returns the kind of extra a file name announces by keyword
+// ("Movie-trailer.mkv", "Behind the Scenes.mkv"). The earliest keyword wins.
+func ExtraKindOf(name string) ExtraKind {
+	best, bestAt := ExtraNone, -1
+	for _, e := range extraKinds {
+		if loc := e.re.FindStringIndex(name); loc != nil && (bestAt < 0 || loc[0] < bestAt) {
+			best, bestAt = e.kind, loc[0]
+		}
+	}
+	return best
+}
+
+// ExtrasDirKind reports whether a directory name denotes an extras folder and
+// which kind it holds. Generic folders (Extras, Bonus) report ExtraNone.
+func ExtrasDirKind(name string) (ExtraKind, bool) {
+	name = strings.TrimSpace(name)
+	if genericExtrasDirRe.MatchString(name) {
+		return ExtraNone, true
+	}
+	for _, e := range extraKinds {
+		if loc := e.re.FindStringIndex(name); loc != nil && loc[0] == 0 && loc[1] == len(name) {
+			return e.kind, true
+		}
+	}
+	return ExtraNone, false
+}
@@ -0,0 +1,64 @@
+package media
+
+import "testing"
+
+func TestExtraKindOf(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in   string
+		want ExtraKind
+	}{
+		{"Movie-trailer.mkv", ExtraTrailer},
+		{"Movie.2020.Teaser.2.mp4", ExtraTrailer},
+		{"Behind the Scenes.mkv", ExtraBehindTheScenes},
+		{"Making.Of.mkv", ExtraFeaturette},
+		{"Movie (2020)-featurette.mkv", ExtraFeaturette},
+		{"Deleted Scenes.mkv", ExtraDeletedScene},
+		{"Movie-deleted.mkv", ExtraDeletedScene},
+		{"Trailer Park Boys (2006).mkv", ExtraTrailer},
+		{"Movie.2020.1080p.mkv", ExtraNone},
+		{"Trailerhood.mkv", ExtraNone},
+	}
+	for _, tc := range tests {
+		if got := ExtraKindOf(tc.in); got != tc.want {
+			t.Errorf("ExtraKindOf(%q) = %d, want %d", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestExtrasDirKind(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in     string
+		want   ExtraKind
+		wantOK bool
+	}{
+		{"Trailers", ExtraTrailer, true},
+		{"featurettes", ExtraFeaturette, true},
+		{"Behind The Scenes", ExtraBehindTheScenes, true},
+		{"Deleted.Scenes", ExtraDeletedScene, true},
+		{"Extras", ExtraNone, true},
+		{"Special Features", ExtraNone, true},
+		{"Season 1", ExtraNone, false},
+		{"Movie Trailers Collection", ExtraNone, false},
+	}
+	for _, tc := range tests {
+		got, ok := ExtrasDirKind(tc.in)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("ExtrasDirKind(%q) = (%d, %v), want (%d, %v)", tc.in, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestExtraKindFolderAndSuffix(t *testing.T) {
+	t.Parallel()
+	if got := ExtraBehindTheScenes.Folder(); got != "Behind The Scenes" {
+		t.Errorf("ExtraBehindTheScenes.Folder() = %q, want %q", got, "Behind The Scenes")
+	}
+	if got := ExtraTrailer.Suffix(); got != "-trailer" {
+		t.Errorf("ExtraTrailer.Suffix() = %q, want %q", got, "-trailer")
+	}
+	if ExtraNone.Folder() != "" || ExtraNone.Suffix() != "" {
+		t.Errorf("ExtraNone folder/suffix = (%q, %q), want empty", ExtraNone.Folder(), ExtraNone.Suffix())
+	}
+}
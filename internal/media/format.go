@@ -2,7 +2,11 @@ package media
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/Digital-Shane/treeview"
 )
@@ -17,35 +21,25 @@ func FormatShowName(name string) string {
 	formatted := name
 	year := ""
 
-	// First, look for a year or year range in the name
-	// Match patterns like "2024", "2024-2025", "2024 2025", etc.
-	yearMatches := yearRangeRe.FindStringSubmatch(formatted)
-
-	if len(yearMatches) > 1 {
-		// Extract just the first year from the match
-		year = yearMatches[1]
-
-		// Find the position of the year match
-		yearIndex := strings.Index(formatted, yearMatches[0])
-		if yearIndex != -1 {
-			// Keep only the part before the year (discard everything after)
-			formatted = formatted[:yearIndex]
-			// If the truncated portion ends with an opening bracket due to an already
-			// formatted name like "Title (2022)", trim it, so we don't duplicate it.
-			formatted = strings.TrimRight(formatted, " ([{-_")
-		}
+	// Look for the release year, or the first year of a range like "2024-2025",
+	// and discard everything after it.
+	if m, ok := findYear(formatted); ok {
+		year = m.year
+		formatted = formatted[:m.start]
+		// If the truncated portion ends with an opening bracket due to an already
+		// formatted name like "Title (2022)", trim it, so we don't duplicate it.
+		formatted = strings.TrimRight(formatted, " ([{-_")
 	}
 
 	// Replace separators with spaces
-	formatted = strings.ReplaceAll(formatted, ".", " ")
-	formatted = strings.ReplaceAll(formatted, "-", " ")
-	formatted = strings.ReplaceAll(formatted, "_", " ")
+	formatted = replaceSeparators(formatted)
 
 	// Remove common encoding tags (in case any remain before the year)
 	formatted = encodingTagsRe.ReplaceAllString(formatted, "")
 
 	// Clean up extra spaces
 	formatted = strings.TrimSpace(strings.Join(strings.Fields(formatted), " "))
+	formatted = applyTitleCase(formatted)
 
 	// Add year in parentheses if we found one
 	if year != "" {
@@ -55,6 +49,110 @@ func FormatShowName(name string) string {
 	return formatted
 }
 
+// yearMatch is the span of a year or year range in a name and the year it names.
+type yearMatch struct {
+	start, end int
+	year       string
+}
+
+// findYear picks the release year of name. Titles may hold years of their own
+// ("2001.A.Space.Odyssey.1968", "Blade.Runner.2049.2017"), so a year only counts
+// when a title precedes it and it is not in the future. A year already written
+// in brackets wins; otherwise the last year before the quality tags is used, or
+// the first one after them ("Show.1080p.2022"). A range ("2023-2024") names its
+// first year.
+func findYear(name string) (yearMatch, bool) {
+	locs := yearRe.FindAllStringIndex(name, -1)
+	var found []yearMatch
+	for i := 0; i < len(locs); i++ {
+		m := yearMatch{start: locs[i][0], end: locs[i][1], year: name[locs[i][0]:locs[i][1]]}
+		if !plausibleYear(m.year) || !hasTitle(name[:m.start]) {
+			continue
+		}
+		if i+1 < len(locs) && isYearRange(name, m, locs[i+1]) {
+			m.end = locs[i+1][1]
+			i++
+		}
+		found = append(found, m)
+	}
+	if len(found) == 0 {
+		return yearMatch{}, false
+	}
+	for i := len(found) - 1; i >= 0; i-- {
+		m := found[i]
+		if inBrackets(name, m.start, m.end) {
+			return m, true
+		}
+	}
+	tags := qualityTagStart(name)
+	best := found[0]
+	for _, m := range found {
+		if m.start < tags {
+			best = m
+		}
+	}
+	return best, true
+}
+
+// isYearRange reports whether the year at next ends the range starting with m:
+// a later year after a dash ("2023-2024"), or after spaces when the range is
+// bracketed ("(2023 2024)"). "Wonder Woman 1984 2020" holds no range.
+func isYearRange(name string, m yearMatch, next []int) bool {
+	if name[next[0]:next[1]] < m.year {
+		return false
+	}
+	sep := name[m.end:next[0]]
+	if yearRangeSepRe.MatchString(sep) {
+		return true
+	}
+	return strings.TrimSpace(sep) == "" && inBrackets(name, m.start, next[1])
+}
+
+// inBrackets reports whether name[start:end] is enclosed in parentheses or square brackets.
+func inBrackets(name string, start, end int) bool {
+	return start > 0 && end < len(name) && strings.ContainsRune("([", rune(name[start-1])) && strings.ContainsRune(")]", rune(name[end]))
+}
+
+// plausibleYear reports whether year can be a release year: no later than next year.
+func plausibleYear(year string) bool {
+	y, err := strconv.Atoi(year)
+	return err == nil && y <= time.Now().Year()+1
+}
+
+// hasTitle reports whether prefix holds anything but separators, so a year after
+// it leaves a title behind.
+func hasTitle(prefix string) bool {
+	return strings.IndexFunc(prefix, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0
+}
+
+// qualityTagStart returns the position of the first resolution, source or codec
+// tag in name, or len(name) when there is none.
+func qualityTagStart(name string) int {
+	start := len(name)
+	for _, re := range []*regexp.Regexp{resolutionRe, sourceRe, codecRe} {
+		if loc := re.FindStringIndex(name); loc != nil && loc[0] < start {
+			start = loc[0]
+		}
+	}
+	return start
+}
+
+// ShowNameFromRelease formats the show title preceding the season or episode token
+// of a release name: "The.Show.2019.S01E02.1080p.mkv" returns "The Show (2019)".
+// Returns an empty string when the name starts with the token or has none.
+func ShowNameFromRelease(name string) string {
+	end := -1
+	for _, re := range []*regexp.Regexp{episodeMarkerRe, releaseSeasonRe} {
+		if loc := re.FindStringIndex(name); loc != nil && (end < 0 || loc[0] < end) {
+			end = loc[0]
+		}
+	}
+	if end <= 0 {
+		return ""
+	}
+	return FormatShowName(name[:end])
+}
+
 // FormatSeasonName extracts season number from input and returns formatted season folder name.
 // Returns a standardized season folder name (e.g., "Season 01") if season is found, empty string if not.
 func FormatSeasonName(input string) string {
@@ -73,14 +171,30 @@ func FormatEpisodeName(input string, node *treeview.Node[treeview.FileInfo]) str
 	if !found {
 		return ""
 	}
+	return formatEpisode(input, season, episode)
+}
 
-	// Preserve the file extension (with language code for subtitles)
-	ext := ""
-	if IsSubtitle(input) {
-		ext = ExtractSubtitleSuffix(input)
-	} else {
-		ext = ExtractExtension(input)
+// FormatEpisodeNameInSeason formats input like FormatEpisodeName for a file moved
+// into the season folder seasonDir. A name without a season of its own takes the
+// season of seasonDir, not of the folder it sits in ("Season 2/Disc 1/03.mkv").
+func FormatEpisodeNameInSeason(input, seasonDir string) string {
+	if season, episode, found := ParseSeasonEpisode(input, nil); found {
+		return formatEpisode(input, season, episode)
 	}
+	episode, ok := firstIntFromRegexps(input, episodeNumberRe)
+	season, found := ExtractSeasonNumber(seasonDir)
+	if !ok || !found {
+		return ""
+	}
+	return formatEpisode(input, season, episode)
+}
 
+// formatEpisode writes "S01E02" followed by the extension of input, keeping the
+// language code and qualifiers of companion files.
+func formatEpisode(input string, season, episode int) string {
+	ext := ExtractCompanionSuffix(input)
+	if ext == "" {
+		ext = ExtractExtension(input)
+	}
 	return fmt.Sprintf("S%02dE%02d%s", season, episode, ext)
 }
@@ -22,9 +22,19 @@ func TestFormatShowName(t *testing.T) {
 		{name: "TagsBeforeYearAreRemoved", input: "Great.Show.1080p.2022.x265", want: "Great Show (2022)"},
 		{name: "SpacingCleanup", input: "The----Show....2021", want: "The Show (2021)"},
 		{name: "AfterYearDiscarded", input: "Show.Name.2024.Extra.Stuff.1080p", want: "Show Name (2024)"},
-		{name: "YearRangeSpaceSeparator", input: "Another.Show 2021 2022 720p", want: "Another Show (2021)"},
+		{name: "YearRangeSpaceSeparator", input: "Another.Show (2021 2022) 720p", want: "Another Show (2021)"},
+		{name: "SpacedYearsAreNoRange", input: "Wonder Woman 1984 2020 2160p", want: "Wonder Woman 1984 (2020)"},
+		{name: "SpacedYearsLastWins", input: "Another Show 2021 2022 720p", want: "Another Show 2021 (2022)"},
+		{name: "SpacedDashRange", input: "Cool Show 2023 - 2024 1080p", want: "Cool Show (2023)"},
 		{name: "PlainNoChange", input: "Plain Show", want: "Plain Show"},
 		{name: "AlreadyFormattedYear", input: "Some Film (2022)", want: "Some Film (2022)"},
+		{name: "LeadingYearIsTitle", input: "2001.A.Space.Odyssey.1968", want: "2001 A Space Odyssey (1968)"},
+		{name: "FutureYearIsTitle", input: "Blade.Runner.2049.2017", want: "Blade Runner 2049 (2017)"},
+		{name: "OnlyFutureYear", input: "Blade.Runner.2049.1080p", want: "Blade Runner 2049"},
+		{name: "YearTitle", input: "1917.2019.1080p", want: "1917 (2019)"},
+		{name: "YearOnlyIsTitle", input: "1917", want: "1917"},
+		{name: "LastYearBeforeTags", input: "Wonder.Woman.1984.2020.2160p.HDR", want: "Wonder Woman 1984 (2020)"},
+		{name: "BracketedYearWins", input: "Movie.1999.Remake.(2021)", want: "Movie 1999 Remake (2021)"},
 	}
 	for _, tc := range tests {
 		tc := tc
@@ -73,6 +83,8 @@ func TestFormatEpisodeName_DirectPattern(t *testing.T) {
 		{name: "VideoBasic", input: "Show.Name.S01E02.1080p.mkv", want: "S01E02.mkv"},
 		{name: "VideoUpperExt", input: "Show.Name.S01E02.1080p.MKV", want: "S01E02.MKV"},
 		{name: "SubtitleLangShort", input: "Show.Name.S01E02.en.srt", want: "S01E02.en.srt"},
+		{name: "ChaptersSidecar", input: "Show.Name.S01E02.chapters.xml", want: "S01E02.chapters.xml"},
+		{name: "AudioTrackLang", input: "Show.Name.S01E02.en.ac3", want: "S01E02.en.ac3"},
 		{name: "SubtitleLangRegion", input: "Show.Name.S01E03.en-US.srt", want: "S01E03.en-US.srt"},
 		{name: "SubtitleNoLang", input: "Show.Name.S01E04.srt", want: "S01E04.srt"},
 		{name: "Subtitle3CharLang", input: "Show.Name.S01E05.eng.srt", want: "S01E05.eng.srt"},
@@ -96,3 +108,41 @@ func TestFormatEpisodeName_DirectPattern(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatEpisodeNameInSeason(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input, seasonDir, want string
+	}{
+		{"03.mkv", "Season 2", "S02E03.mkv"},
+		{"Episode 5.en.srt", "Season 02", "S02E05.en.srt"},
+		{"Show.S03E04.mkv", "Season 2", "S03E04.mkv"}, // the name's own season wins
+		{"03.mkv", "Specials", ""},
+		{"cover.jpg", "Season 2", ""},
+	}
+	for _, tc := range tests {
+		if got := FormatEpisodeNameInSeason(tc.input, tc.seasonDir); got != tc.want {
+			t.Errorf("FormatEpisodeNameInSeason(%q, %q) = %q, want %q", tc.input, tc.seasonDir, got, tc.want)
+		}
+	}
+}
+
+func TestShowNameFromRelease(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"The.Show.2019.S01E02.1080p.mkv", "The Show (2019)"},
+		{"Show.Name.S02.1080p.WEB-DL", "Show Name"},
+		{"My Show Season 3", "My Show"},
+		{"Show.1x02.mkv", "Show"},
+		{"S01E02.mkv", ""},
+		{"Movie.2020.1080p.mkv", ""},
+	}
+	for _, tc := range tests {
+		if got := ShowNameFromRelease(tc.input); got != tc.want {
+			t.Errorf("ShowNameFromRelease(%q) = %q, want %q", tc.input, got, tc.want)
+		}
+	}
+}
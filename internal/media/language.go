@@ -0,0 +1,138 @@
+package media
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LanguageForm selects how subtitle languages are written in new names.
+type LanguageForm int
+
+const (
+	LanguageKeep    LanguageForm = iota // Keep codes as written; language names become ISO 639-1
+	LanguageISO6391                     // Two-letter codes: en, de, pt-BR
+	LanguageISO6392                     // Three-letter bibliographic codes: eng, ger, por-BR
+)
+
+// language is one entry of the built-in language table.
+type language struct {
+	iso1, iso2B, iso2T string
+	names              []string // lower-case English and native names
+}
+
+// languages covers the languages commonly found in subtitle releases.
+var languages = []language{
+	{"en", "eng", "", []string{"english"}},
+	{"fr", "fre", "fra", []string{"french", "francais", "français"}},
+	{"de", "ger", "deu", []string{"german", "deutsch"}},
+	{"es", "spa", "", []string{"spanish", "espanol", "español", "castellano"}},
+	{"it", "ita", "", []string{"italian", "italiano"}},
+	{"pt", "por", "", []string{"portuguese", "portugues", "português"}},
+	{"nl", "dut", "nld", []string{"dutch", "nederlands"}},
+	{"sv", "swe", "", []string{"swedish", "svenska"}},
+	{"no", "nor", "", []string{"norwegian", "norsk"}},
+	{"nb", "nob", "", []string{"bokmal", "bokmål"}},
+	{"da", "dan", "", []string{"danish", "dansk"}},
+	{"fi", "fin", "", []string{"finnish", "suomi"}},
+	{"is", "ice", "isl", []string{"icelandic"}},
+	{"pl", "pol", "", []string{"polish", "polski"}},
+	{"cs", "cze", "ces", []string{"czech", "cesky", "čeština"}},
+	{"sk", "slo", "slk", []string{"slovak"}},
+	{"hu", "hun", "", []string{"hungarian", "magyar"}},
+	{"ro", "rum", "ron", []string{"romanian", "romana"}},
+	{"bg", "bul", "", []string{"bulgarian"}},
+	{"hr", "hrv", "", []string{"croatian", "hrvatski"}},
+	{"sr", "srp", "", []string{"serbian", "srpski"}},
+	{"sl", "slv", "", []string{"slovenian", "slovene"}},
+	{"bs", "bos", "", []string{"bosnian"}},
+	{"mk", "mac", "mkd", []string{"macedonian"}},
+	{"sq", "alb", "sqi", []string{"albanian"}},
+	{"el", "gre", "ell", []string{"greek"}},
+	{"tr", "tur", "", []string{"turkish", "turkce", "türkçe"}},
+	{"ru", "rus", "", []string{"russian"}},
+	{"uk", "ukr", "", []string{"ukrainian"}},
+	{"et", "est", "", []string{"estonian"}},
+	{"lv", "lav", "", []string{"latvian"}},
+	{"lt", "lit", "", []string{"lithuanian"}},
+	{"he", "heb", "", []string{"hebrew"}},
+	{"ar", "ara", "", []string{"arabic"}},
+	{"fa", "per", "fas", []string{"persian", "farsi"}},
+	{"hi", "hin", "", []string{"hindi"}},
+	{"bn", "ben", "", []string{"bengali"}},
+	{"ta", "tam", "", []string{"tamil"}},
+	{"te", "tel", "", []string{"telugu"}},
+	{"th", "tha", "", []string{"thai"}},
+	{"vi", "vie", "", []string{"vietnamese"}},
+	{"id", "ind", "", []string{"indonesian"}},
+	{"ms", "may", "msa", []string{"malay"}},
+	{"tl", "tgl", "", []string{"tagalog", "filipino"}},
+	{"zh", "chi", "zho", []string{"chinese", "mandarin"}},
+	{"ja", "jpn", "", []string{"japanese"}},
+	{"ko", "kor", "", []string{"korean"}},
+	{"ca", "cat", "", []string{"catalan"}},
+	{"eu", "baq", "eus", []string{"basque"}},
+	{"gl", "glg", "", []string{"galician"}},
+}
+
+// languageIndex maps every code and name in languages (lower-case) to its entry.
+var languageIndex = func() map[string]*language {
+	idx := map[string]*language{}
+	for i := range languages {
+		l := &languages[i]
+		for _, key := range append([]string{l.iso1, l.iso2B, l.iso2T}, l.names...) {
+			if key != "" {
+				idx[key] = l
+			}
+		}
+	}
+	return idx
+}()
+
+// languageForm is the active output form, set once at startup by SetLanguageForm.
+var languageForm = LanguageKeep
+
+// SetLanguageForm selects the form NormalizeLanguage writes. It is not safe to
+// call while names are being formatted.
+func SetLanguageForm(f LanguageForm) { languageForm = f }
+
+// ParseLanguageForm resolves the --sub-lang values keep, 639-1 and 639-2.
+func ParseLanguageForm(s string) (LanguageForm, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "keep":
+		return LanguageKeep, nil
+	case "639-1", "iso639-1", "2":
+		return LanguageISO6391, nil
+	case "639-2", "iso639-2", "3":
+		return LanguageISO6392, nil
+	}
+	return LanguageKeep, fmt.Errorf("unknown language form %q (want keep, 639-1 or 639-2)", s)
+}
+
+// LookupLanguage reports whether token is a known ISO 639 code or language name.
+func LookupLanguage(token string) bool {
+	_, ok := languageIndex[strings.ToLower(token)]
+	return ok
+}
+
+// NormalizeLanguage writes a language token (code or name, with an optional
+// region such as "pt-BR" or "en_us") in the active LanguageForm. Unknown codes
+// are returned unchanged; unknown names yield "".
+func NormalizeLanguage(token string) string {
+	code, region, hasRegion := strings.Cut(strings.ReplaceAll(token, "_", "-"), "-")
+	l, known := languageIndex[strings.ToLower(code)]
+	isCode := len(code) <= 3
+	switch {
+	case !known && !isCode:
+		return ""
+	case !known || (languageForm == LanguageKeep && isCode):
+		return token
+	}
+	out := l.iso1
+	if languageForm == LanguageISO6392 {
+		out = l.iso2B
+	}
+	if hasRegion {
+		out += "-" + strings.ToUpper(region)
+	}
+	return out
+}
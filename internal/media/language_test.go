@@ -0,0 +1,44 @@
+package media
+
+import "testing"
+
+func TestNormalizeLanguage(t *testing.T) {
+	defer SetLanguageForm(LanguageKeep)
+	tests := []struct {
+		form LanguageForm
+		in   string
+		want string
+	}{
+		{LanguageKeep, "eng", "eng"},
+		{LanguageKeep, "en-US", "en-US"},
+		{LanguageKeep, "English", "en"},
+		{LanguageISO6391, "eng", "en"},
+		{LanguageISO6391, "ger", "de"},
+		{LanguageISO6391, "deu", "de"},
+		{LanguageISO6391, "pt_br", "pt-BR"},
+		{LanguageISO6391, "French", "fr"},
+		{LanguageISO6392, "en", "eng"},
+		{LanguageISO6392, "de", "ger"},
+		{LanguageISO6392, "Spanish", "spa"},
+		{LanguageISO6391, "xx", "xx"},
+		{LanguageISO6391, "Klingon", ""},
+	}
+	for _, tc := range tests {
+		SetLanguageForm(tc.form)
+		if got := NormalizeLanguage(tc.in); got != tc.want {
+			t.Errorf("NormalizeLanguage(%q) with form %d = %q, want %q", tc.in, tc.form, got, tc.want)
+		}
+	}
+}
+
+func TestParseLanguageForm(t *testing.T) {
+	t.Parallel()
+	for in, want := range map[string]LanguageForm{"keep": LanguageKeep, "639-1": LanguageISO6391, "639-2": LanguageISO6392} {
+		if got, err := ParseLanguageForm(in); err != nil || got != want {
+			t.Errorf("ParseLanguageForm(%q) = (%d,%v), want %d", in, got, err, want)
+		}
+	}
+	if _, err := ParseLanguageForm("639-3"); err == nil {
+		t.Errorf("ParseLanguageForm(639-3) error = nil, want error")
+	}
+}
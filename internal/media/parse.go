@@ -30,14 +30,12 @@ var (
 	// and cap the season to two digits to avoid capturing a leading year like 2024.05.
 	dottedSeasonEpisodeRe = regexp.MustCompile(`(?i)^(?:|[\s_\-\.])([0-9]{1,2})[\. _-]([0-9]{1,2})(?:[^0-9]|$)`)
 
-	// videoRe matches video file extensions used to include media files.
-	videoRe = regexp.MustCompile(`(?i)\.(mp4|mkv|avi|mov|wmv|flv|webm|mpeg|mpg|m4v|3gp|vob|ts|mts|m2ts|rmvb|divx)$`)
+	// yearRe matches a standalone year token; findYear decides which one is the release year.
+	yearRe = regexp.MustCompile(`\b(?:19|20)\d{2}\b`)
 
-	// subtitleRe matches subtitle file extensions (case‑insensitive).
-	subtitleRe = regexp.MustCompile(`(?i)\.(srt|sub|idx|ass|ssa|smi|vtt|sbv|sami|usf|stl|dks|pjs|jss|psb|rt|scc|cap|sup|dfxp|ttml)$`)
-
-	// yearRangeRe extracts a year or year range; only the first year is used in output.
-	yearRangeRe = regexp.MustCompile(`\b((19|20)\d{2})(?:[\s\-–—]+(?:19|20)\d{2})?\b`)
+	// yearRangeSepRe matches the dash between the two years of a range such as "2023-2024".
+	// Years separated by spaces only form a range inside brackets: "(2023 2024)".
+	yearRangeSepRe = regexp.MustCompile(`^\s*[\-–—]\s*$`)
 
 	// episodeNumberRe captures a loose episode number when SxxExx not present.
 	episodeNumberRe = regexp.MustCompile(`(?:^|[\s\.\-_]|[Ee])(\d+)(?:[\s\.\-_]|$)`)
@@ -45,60 +43,91 @@ var (
 	// encodingTagsRe removes codec/resolution/source tags to isolate the series title.
 	encodingTagsRe = regexp.MustCompile(`(?i)\b(?:HD|HDR|DV|x265|x264|H\.?264|H\.?265|HEVC|AVC|AAC|AC3|DD|DTS|FLAC|MP3|WEB-?DL|BluRay|BDRip|DVDRip|HDTV|720p|1080p|2160p|4K|UHD|SDR|10bit|8bit|PROPER|REPACK|iNTERNAL|LiMiTED|UNRATED|EXTENDED|DiRECTORS?\.?CUT|THEATRICAL|COMPLETE|SEASON|SERIES|MULTI|DUAL|DUBBED|SUBBED|SUB|RETAIL|WS|FS|NTSC|PAL|R[1-6]|UNCUT|UNCENSORED)\b`)
 
-	// langPattern matches trailing language codes before subtitle extension: .en, .eng, .en-US.
-	langPattern = regexp.MustCompile(`(\.[a-zA-Z]{2,3}(?:[-_][a-zA-Z]{2,4})?)$`)
-
 	// simpleNumberRe matches a standalone number that might represent a season.
 	simpleNumberRe = regexp.MustCompile(`^(\d+)|[\s\.\-_](\d+)(?:[\s\.\-_]|$)`)
 
-	// nfoRe matches NFO info file extensions.
-	nfoRe = regexp.MustCompile(`(?i)\.nfo$`)
+	// sampleRe matches a standalone "sample" token: sample.mkv, Movie.2020.SAMPLE.mkv, movie-sample.mkv.
+	sampleRe = regexp.MustCompile(`(?i)(?:^|[\s\.\-_])sample(?:[\s\.\-_]|$)`)
+
+	// sampleDirRe matches folders that only hold samples: Sample, samples.
+	sampleDirRe = regexp.MustCompile(`(?i)^samples?$`)
+
+	// discDirRe matches the folders of DVD and Blu-ray structures: VIDEO_TS, BDMV, CERTIFICATE.
+	discDirRe = regexp.MustCompile(`(?i)^(?:video_ts|audio_ts|hvdvd_ts|bdmv|certificate)$`)
+
+	// subsDirRe matches folders releases use for subtitles: Subs, Subtitles.
+	subsDirRe = regexp.MustCompile(`(?i)^(?:subs|subtitles?)$`)
 
-	// imageRe matches common image file extensions.
-	imageRe = regexp.MustCompile(`(?i)\.(jpg|jpeg|png|gif|bmp|webp|tiff?|ico|svg)$`)
+	// episodeMarkerRe matches explicit episode markers only (S01E02, s1.e2, 1x02), unlike
+	// seasonEpisodeRe which also accepts bare number pairs.
+	episodeMarkerRe = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])(?:s\d{1,2}[\s\.\-_]?e\d{1,3}|\d{1,2}x\d{2,3})(?:[^0-9]|$)`)
+
+	// seasonDirRe matches folders holding a single season: Season 1, S01, Series 2, Specials.
+	seasonDirRe = regexp.MustCompile(`(?i)^(?:(?:season|series|s)[\s\.\-_]*\d{1,3}|specials?)$`)
+
+	// releaseSeasonRe finds where the season token starts in a release name: Show.Name.S01.1080p, Show Season 2.
+	releaseSeasonRe = regexp.MustCompile(`(?i)(?:^|[\s\.\-_])(?:s|season[\s\.\-_]*)\d{1,2}(?:[\s\.\-_]|e\d|$)`)
 )
 
-// IsVideo reports whether filename has a recognized video extension.
+// tokenRe builds a pattern matching words as a standalone token, words being
+// separated by any of the usual release separators.
+func tokenRe(words string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)(?:^|[\s._\-\[(])` + words + `(?:[\s._\-\])]|$)`)
+}
+
+// IsVideo reports whether filename has a registered video extension.
 func IsVideo(filename string) bool {
-	return videoRe.MatchString(filename)
+	return CategoryOf(filename) == CategoryVideo
 }
 
-// IsSubtitle reports whether filename has a recognized subtitle extension.
+// IsSubtitle reports whether filename has a registered subtitle extension.
 func IsSubtitle(filename string) bool {
-	return subtitleRe.MatchString(filename)
+	return CategoryOf(filename) == CategorySubtitle
+}
+
+// IsAudio reports whether filename has a registered external audio extension.
+func IsAudio(filename string) bool {
+	return CategoryOf(filename) == CategoryAudio
 }
 
-// IsNFO reports whether filename has an NFO extension.
+// IsNFO reports whether filename has a registered NFO extension.
 func IsNFO(filename string) bool {
-	return nfoRe.MatchString(filename)
+	return CategoryOf(filename) == CategoryNFO
 }
 
-// IsImage reports whether filename has a recognized image extension.
+// IsImage reports whether filename has a registered image extension.
 func IsImage(filename string) bool {
-	return imageRe.MatchString(filename)
+	return CategoryOf(filename) == CategoryImage
 }
 
-// ExtractSubtitleSuffix extracts the language code and extension from subtitle files.
-// For example: "movie.en.srt" returns ".en.srt", "movie.srt" returns ".srt"
-// Also handles cases like "movie.eng.srt", "movie.en-US.srt", etc.
-func ExtractSubtitleSuffix(filename string) string {
-	if !IsSubtitle(filename) {
-		return ""
-	}
+// IsSample reports whether a video filename is marked as a release sample.
+func IsSample(filename string) bool {
+	return IsVideo(filename) && sampleRe.MatchString(filename)
+}
 
-	// Find the subtitle extension first
-	subtitleMatch := subtitleRe.FindStringIndex(filename)
-	if len(subtitleMatch) == 0 {
-		return ""
-	}
+// IsSampleDir reports whether a directory name denotes a sample folder.
+func IsSampleDir(name string) bool {
+	return sampleDirRe.MatchString(name)
+}
 
-	// Look for language codes before the subtitle extension
-	// Matches patterns like .en, .eng, .en-US, .en_US, etc.
-	beforeExt := filename[:subtitleMatch[0]]
-	langMatch := langPattern.FindString(beforeExt)
+// IsDiscDir reports whether a directory name is part of a DVD or Blu-ray structure.
+func IsDiscDir(name string) bool {
+	return discDirRe.MatchString(name)
+}
+
+// IsSubsDir reports whether a directory name denotes a subtitle folder.
+func IsSubsDir(name string) bool {
+	return subsDirRe.MatchString(strings.TrimSpace(name))
+}
+
+// HasEpisodeMarker reports whether name carries an explicit episode marker such as S01E02 or 1x02.
+func HasEpisodeMarker(name string) bool {
+	return episodeMarkerRe.MatchString(name)
+}
 
-	// Return language code + subtitle extension
-	return langMatch + filename[subtitleMatch[0]:]
+// IsSeasonDir reports whether a directory name denotes a single season folder.
+func IsSeasonDir(name string) bool {
+	return seasonDirRe.MatchString(strings.TrimSpace(name))
 }
 
 // ExtractSeasonNumber attempts to extract a season number from a string.
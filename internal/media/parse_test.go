@@ -199,3 +199,61 @@ func TestFirstIntFromRegexps_EmptySubmatch(t *testing.T) {
 		t.Errorf("firstIntFromRegexps with empty submatch = (%d,%v), want (123,true)", got, ok)
 	}
 }
+
+func TestIsSample(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"sample.mkv", true},
+		{"Movie.Name.2020.SAMPLE.mkv", true},
+		{"movie-sample.mkv", true},
+		{"Movie Sample.mp4", true},
+		{"sample.srt", false},
+		{"Samples.Of.Life.2020.mkv", false},
+		{"Sampler.mkv", false},
+	}
+	for _, tc := range tests {
+		if got := IsSample(tc.in); got != tc.want {
+			t.Errorf("IsSample(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+	for in, want := range map[string]bool{"Sample": true, "samples": true, "Sample Movie": false} {
+		if got := IsSampleDir(in); got != want {
+			t.Errorf("IsSampleDir(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestHasEpisodeMarker(t *testing.T) {
+	t.Parallel()
+	for in, want := range map[string]bool{
+		"Show.S01E02.1080p.mkv":    true,
+		"show s1.e2.mkv":           true,
+		"Show 1x02.mkv":            true,
+		"Movie.2020.1920x1080.mkv": false,
+		"Movie.2020.x264.mkv":      false,
+		"Show.S01.1080p":           false,
+	} {
+		if got := HasEpisodeMarker(in); got != want {
+			t.Errorf("HasEpisodeMarker(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestIsSeasonDir(t *testing.T) {
+	t.Parallel()
+	for in, want := range map[string]bool{
+		"Season 1":       true,
+		"S01":            true,
+		"series_2":       true,
+		"Specials":       true,
+		"Show.S01.1080p": false,
+		"Seasoned Chef":  false,
+	} {
+		if got := IsSeasonDir(in); got != want {
+			t.Errorf("IsSeasonDir(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package media
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Release quality parsing.
+//
+// These are the same resolution / source / codec tokens that encodingTagsRe strips
+// from titles, parsed into ranks so two copies of the same episode or movie can be
+// compared when deciding which one to keep.
+var (
+	// resolutionRe matches vertical resolution tokens: 2160p, 1080i, 720p, 4K, UHD.
+	resolutionRe = regexp.MustCompile(`(?i)\b(?:(2160|1440|1080|720|576|480)[pi]|4K|UHD)\b`)
+
+	// sourceRe matches release source tokens, most specific alternatives first.
+	sourceRe = regexp.MustCompile(`(?i)\b(REMUX|BluRay|Blu-Ray|BDRip|BRRip|WEB-?DL|WEBRip|WEB|HDTV|DVDRip|DVD|HDTS|TELESYNC|TS|CAM)\b`)
+
+	// codecRe matches video codec tokens.
+	codecRe = regexp.MustCompile(`(?i)\b(AV1|x265|H\.?265|HEVC|x264|H\.?264|AVC|XviD|DivX)\b`)
+
+	sourceRanks = map[string]int{
+		"cam": 1, "ts": 2, "telesync": 2, "hdts": 2,
+		"dvd": 3, "dvdrip": 3, "hdtv": 4,
+		"webrip": 5, "web": 6, "webdl": 6, "web-dl": 6,
+		"bdrip": 7, "brrip": 7, "bluray": 8, "blu-ray": 8, "remux": 9,
+	}
+
+	codecRanks = map[string]int{
+		"xvid": 1, "divx": 1,
+		"x264": 2, "h264": 2, "h.264": 2, "avc": 2,
+		"x265": 3, "h265": 3, "h.265": 3, "hevc": 3,
+		"av1": 4,
+	}
+)
+
+// Quality describes the release attributes parsed from a filename. Each rank is
+// zero when the corresponding token was not present.
+type Quality struct {
+	Resolution int // Vertical resolution (e.g. 1080); 0 when unknown
+	Source     int // Rank of the release source (CAM < HDTV < WEB-DL < BluRay < REMUX)
+	Codec      int // Rank of the video codec (XviD < x264 < x265 < AV1)
+	tokens     []string
+}
+
+// ParseQuality extracts resolution, source and codec information from name.
+func ParseQuality(name string) Quality {
+	q := Quality{}
+	if m := resolutionRe.FindStringSubmatch(name); m != nil {
+		q.tokens = append(q.tokens, m[0])
+		if m[1] != "" {
+			q.Resolution, _ = strconv.Atoi(m[1])
+		} else {
+			q.Resolution = 2160
+		}
+	}
+	if m := sourceRe.FindString(name); m != "" {
+		q.tokens = append(q.tokens, m)
+		q.Source = sourceRanks[strings.ToLower(m)]
+	}
+	if m := codecRe.FindString(name); m != "" {
+		q.tokens = append(q.tokens, m)
+		q.Codec = codecRanks[strings.ToLower(m)]
+	}
+	return q
+}
+
+// Known reports whether any quality attribute was parsed.
+func (q Quality) Known() bool {
+	return q.Resolution != 0 || q.Source != 0 || q.Codec != 0
+}
+
+// Compare ranks q against o by resolution, then source, then codec. Only
+// attributes known on both sides are compared, so an untagged file never loses
+// to a tagged one by default. Returns -1, 0 or 1.
+func (q Quality) Compare(o Quality) int {
+	for _, pair := range [][2]int{{q.Resolution, o.Resolution}, {q.Source, o.Source}, {q.Codec, o.Codec}} {
+		if pair[0] == 0 || pair[1] == 0 || pair[0] == pair[1] {
+			continue
+		}
+		if pair[0] > pair[1] {
+			return 1
+		}
+		return -1
+	}
+	return 0
+}
+
+// String returns the original tokens that were recognized, or "unknown".
+func (q Quality) String() string {
+	if len(q.tokens) == 0 {
+		return "unknown"
+	}
+	return strings.Join(q.tokens, " ")
+}
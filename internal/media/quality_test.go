@@ -0,0 +1,49 @@
+package media
+
+import "testing"
+
+func TestParseQuality(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in   string
+		want Quality
+		str  string
+	}{
+		{"Show.S01E02.1080p.BluRay.x265-GRP.mkv", Quality{Resolution: 1080, Source: 8, Codec: 3}, "1080p BluRay x265"},
+		{"Show.S01E02.720p.HDTV.x264.mkv", Quality{Resolution: 720, Source: 4, Codec: 2}, "720p HDTV x264"},
+		{"Movie.2020.4K.WEB-DL.mkv", Quality{Resolution: 2160, Source: 6}, "4K WEB-DL"},
+		{"S01E02.mkv", Quality{}, "unknown"},
+	}
+	for _, tc := range tests {
+		got := ParseQuality(tc.in)
+		if got.Resolution != tc.want.Resolution || got.Source != tc.want.Source || got.Codec != tc.want.Codec {
+			t.Errorf("ParseQuality(%q) = %+v, want %+v", tc.in, got, tc.want)
+		}
+		if got.String() != tc.str {
+			t.Errorf("ParseQuality(%q).String() = %q, want %q", tc.in, got.String(), tc.str)
+		}
+		if got.Known() != (tc.str != "unknown") {
+			t.Errorf("ParseQuality(%q).Known() = %v", tc.in, got.Known())
+		}
+	}
+}
+
+func TestQualityCompare(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"x.1080p.mkv", "x.720p.mkv", 1},
+		{"x.720p.BluRay.mkv", "x.1080p.HDTV.mkv", -1},
+		{"x.1080p.WEB-DL.mkv", "x.1080p.BluRay.mkv", -1},
+		{"x.1080p.BluRay.x265.mkv", "x.1080p.BluRay.x264.mkv", 1},
+		{"x.1080p.mkv", "S01E02.mkv", 0}, // unknown side never loses by default
+		{"x.HDTV.mkv", "x.1080p.mkv", 0}, // no shared attribute
+	}
+	for _, tc := range tests {
+		if got := ParseQuality(tc.a).Compare(ParseQuality(tc.b)); got != tc.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package media
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// stackRe matches a stacking token ending a movie name: Movie.2005.CD1, Movie (2005) - part 2,
+// Movie [disc b]. The token must be separated from the name before it.
+var stackRe = regexp.MustCompile(`(?i)^(.+?)(?:[\s._\-]+[\[(]?|[\[(])(?:cd|dvd|part|pt|disc|disk)[\s._\-]*([1-9][0-9]?|[a-d])[\])]?$`)
+
+// SplitStack splits a file name without extension into the movie name and part
+// number when it ends with a cd, dvd, part, pt, disc or disk token. Letters a-d
+// count as parts 1-4.
+func SplitStack(base string) (string, int, bool) {
+	m := stackRe.FindStringSubmatch(base)
+	if m == nil {
+		return base, 0, false
+	}
+	part, err := strconv.Atoi(m[2])
+	if err != nil {
+		part = int(strings.ToLower(m[2])[0]-'a') + 1
+	}
+	return m[1], part, true
+}
+
+// StackPart returns the stack name and part number of a stacked movie file or
+// companion, e.g. "Movie.2005" and 2 for "Movie.2005.CD2.avi" or
+// "Movie.2005.cd2.en.srt", looking past the qualifiers.
+func StackPart(filename string) (string, int, bool) {
+	for _, suffix := range append([]string{ExtractExtension(filename)}, CompanionSuffixes(filename)...) {
+		if stem, part, ok := SplitStack(filename[:len(filename)-len(suffix)]); ok {
+			return stem, part, true
+		}
+	}
+	return "", 0, false
+}
+
+// FormatPart returns the part suffix added to stacked movie names: " - pt2".
+func FormatPart(part int) string {
+	return fmt.Sprintf(" - pt%d", part)
+}
@@ -0,0 +1,48 @@
+package media
+
+import "testing"
+
+func TestSplitStack(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in       string
+		wantStem string
+		wantPart int
+		wantOK   bool
+	}{
+		{"Movie.2005.CD1", "Movie.2005", 1, true},
+		{"Movie (2005) - part 2", "Movie (2005)", 2, true},
+		{"Movie.2005.pt3", "Movie.2005", 3, true},
+		{"Movie 2005 [disc b]", "Movie 2005", 2, true},
+		{"Movie_2005_DVD-2", "Movie_2005", 2, true},
+		{"Harry.Potter.and.the.Deathly.Hallows.Part.1.2010", "Harry.Potter.and.the.Deathly.Hallows.Part.1.2010", 0, false},
+		{"Movie.2005.DVDRip", "Movie.2005.DVDRip", 0, false},
+		{"cd1", "cd1", 0, false},
+	}
+	for _, tc := range tests {
+		stem, part, ok := SplitStack(tc.in)
+		if stem != tc.wantStem || part != tc.wantPart || ok != tc.wantOK {
+			t.Errorf("SplitStack(%q) = (%q, %d, %v), want (%q, %d, %v)", tc.in, stem, part, ok, tc.wantStem, tc.wantPart, tc.wantOK)
+		}
+	}
+}
+
+func TestStackPart(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in       string
+		wantStem string
+		want     int
+		wantOK   bool
+	}{
+		{"Movie.2005.CD2.avi", "Movie.2005", 2, true},
+		{"Movie.2005.cd2.en.srt", "Movie.2005", 2, true},
+		{"Movie.2005.CD1.commentary.ac3", "Movie.2005", 1, true},
+		{"Movie.2005.avi", "", 0, false},
+	}
+	for _, tc := range tests {
+		if stem, got, ok := StackPart(tc.in); stem != tc.wantStem || got != tc.want || ok != tc.wantOK {
+			t.Errorf("StackPart(%q) = (%q, %d, %v), want (%q, %d, %v)", tc.in, stem, got, ok, tc.wantStem, tc.want, tc.wantOK)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package media
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// langTokenRe matches a language code token with an optional region: en, eng, pt-BR, en_US.
+var langTokenRe = regexp.MustCompile(`^[a-zA-Z]{2,3}(?:[-_][a-zA-Z]{2,4})?$`)
+
+// trackNumberRe matches the track number leading subtitle names in Subs folders: 2_, 03 - .
+var trackNumberRe = regexp.MustCompile(`^\d+[\s._-]*`)
+
+// maxSubtitleTags bounds the qualifier tokens read from a subtitle name: a language
+// plus the default, forced and sdh flags.
+const maxSubtitleTags = 4
+
+// SubtitleTags describes a subtitle track as declared by its filename qualifiers.
+type SubtitleTags struct {
+	Language string // language token as written (code or name, region included); "" when absent
+	Default  bool   // .default
+	Forced   bool   // .forced or .foreign
+	SDH      bool   // .sdh, .cc or .hi (hearing impaired)
+}
+
+// ParseSubtitleTags reads the qualifiers between a subtitle's base name and its
+// extension, e.g. "Movie.eng.sdh.srt" or "Movie.English.forced.srt". It returns
+// the tags and the raw suffix they were read from, extension included.
+func ParseSubtitleTags(filename string) (SubtitleTags, string) {
+	tags, cuts := parseSubtitleTokens(filename)
+	return tags, filename[cuts[len(cuts)-1]:]
+}
+
+// Suffix renders the tags with ext in the order Plex and Jellyfin expect:
+// language (in the active LanguageForm), then default, forced and sdh.
+func (s SubtitleTags) Suffix(ext string) string {
+	var b strings.Builder
+	if lang := NormalizeLanguage(s.Language); lang != "" {
+		b.WriteString("." + lang)
+	}
+	for _, flag := range []struct {
+		set  bool
+		name string
+	}{{s.Default, "default"}, {s.Forced, "forced"}, {s.SDH, "sdh"}} {
+		if flag.set {
+			b.WriteString("." + flag.name)
+		}
+	}
+	return b.String() + ext
+}
+
+// ExtractSubtitleSuffix extracts the language code, flags and extension from subtitle
+// files, normalized for the media server.
+// For example: "movie.en.srt" returns ".en.srt", "movie.srt" returns ".srt" and
+// "Movie.English.SDH.forced.srt" returns ".en.forced.sdh.srt".
+// Also handles cases like "movie.eng.srt", "movie.en-US.srt", etc.
+func ExtractSubtitleSuffix(filename string) string {
+	if !IsSubtitle(filename) {
+		return ""
+	}
+	tags, _ := ParseSubtitleTags(filename)
+	return tags.Suffix(ExtractExtension(filename))
+}
+
+// NormalizeCompanionSuffix rewrites a raw companion suffix (as returned by
+// CompanionSuffixes) in canonical form. Only subtitle suffixes change.
+func NormalizeCompanionSuffix(suffix string) string {
+	if !IsSubtitle(suffix) {
+		return suffix
+	}
+	tags, _ := parseSubtitleTokens(suffix)
+	return tags.Suffix(ExtractExtension(suffix))
+}
+
+// parseSubtitleTokens consumes qualifier tokens from the right of filename. cuts
+// holds the start of the suffix after each consumed token, beginning with the
+// extension alone. A lone "hi" is Hindi; next to another language it flags SDH.
+func parseSubtitleTokens(filename string) (SubtitleTags, []int) {
+	var tags SubtitleTags
+	ext := ExtractExtension(filename)
+	rest := filename[:len(filename)-len(ext)]
+	cuts := []int{len(rest)}
+	hi := false
+	for range maxSubtitleTags {
+		dot := strings.LastIndex(rest, ".")
+		if dot < 0 {
+			break
+		}
+		tok := rest[dot+1:]
+		switch low := strings.ToLower(tok); {
+		case tags.setFlag(low):
+		case low == "hi" && !hi:
+			hi = true
+		case tags.Language == "" && (langTokenRe.MatchString(tok) || LookupLanguage(tok)):
+			tags.Language = tok
+		default:
+			return resolveHindi(tags, hi), cuts
+		}
+		rest = rest[:dot]
+		cuts = append(cuts, dot)
+	}
+	return resolveHindi(tags, hi), cuts
+}
+
+// GuessSubtitleTags reads the tags of a subtitle whose name is only a track
+// number, language and flags, as found in release Subs folders: "2_English.srt",
+// "3_eng.srt", "English (SDH).srt". Names following the "<video>.<lang>.srt"
+// convention are parsed like ParseSubtitleTags.
+func GuessSubtitleTags(filename string) SubtitleTags {
+	stem := strings.TrimSuffix(filename, ExtractExtension(filename))
+	stem = trackNumberRe.ReplaceAllString(stem, "")
+	var guess SubtitleTags
+	for _, tok := range strings.FieldsFunc(stem, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		switch low := strings.ToLower(tok); {
+		case guess.setFlag(low):
+		case guess.Language == "" && LookupLanguage(tok):
+			guess.Language = tok
+		default: // not a bare track name
+			tags, _ := ParseSubtitleTags(filename)
+			return tags
+		}
+	}
+	return guess
+}
+
+// SubtitleTrack returns the track number leading a subtitle name from a Subs
+// folder ("3" for "3_English.srt"), or "" when there is none.
+func SubtitleTrack(filename string) string {
+	return strings.TrimRight(trackNumberRe.FindString(filename), " ._-")
+}
+
+// setFlag sets the flag named by the lower-cased token low, reporting whether it was one.
+func (s *SubtitleTags) setFlag(low string) bool {
+	switch low {
+	case "forced", "foreign":
+		s.Forced = true
+	case "sdh", "cc":
+		s.SDH = true
+	case "default":
+		s.Default = true
+	default:
+		return false
+	}
+	return true
+}
+
+// resolveHindi settles an ambiguous "hi" token once the other tokens are known.
+func resolveHindi(tags SubtitleTags, hi bool) SubtitleTags {
+	switch {
+	case !hi:
+	case tags.Language == "":
+		tags.Language = "hi"
+	default:
+		tags.SDH = true
+	}
+	return tags
+}
@@ -0,0 +1,93 @@
+package media
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseSubtitleTags(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in      string
+		want    SubtitleTags
+		wantRaw string
+	}{
+		{"Movie.en.forced.srt", SubtitleTags{Language: "en", Forced: true}, ".en.forced.srt"},
+		{"Movie.eng.sdh.srt", SubtitleTags{Language: "eng", SDH: true}, ".eng.sdh.srt"},
+		{"Movie.English.CC.default.srt", SubtitleTags{Language: "English", SDH: true, Default: true}, ".English.CC.default.srt"},
+		{"Movie.foreign.srt", SubtitleTags{Forced: true}, ".foreign.srt"},
+		{"Movie.hi.srt", SubtitleTags{Language: "hi"}, ".hi.srt"},
+		{"Movie.en.hi.srt", SubtitleTags{Language: "en", SDH: true}, ".en.hi.srt"},
+		{"Movie.2020.pt-BR.srt", SubtitleTags{Language: "pt-BR"}, ".pt-BR.srt"},
+		{"Movie.2020.srt", SubtitleTags{}, ".srt"},
+	}
+	for _, tc := range tests {
+		got, raw := ParseSubtitleTags(tc.in)
+		if got != tc.want || raw != tc.wantRaw {
+			t.Errorf("ParseSubtitleTags(%q) = (%+v, %q), want (%+v, %q)", tc.in, got, raw, tc.want, tc.wantRaw)
+		}
+	}
+}
+
+func TestExtractSubtitleSuffixFlags(t *testing.T) {
+	defer SetLanguageForm(LanguageKeep)
+	tests := []struct {
+		form LanguageForm
+		in   string
+		want string
+	}{
+		{LanguageKeep, "Movie.en.forced.srt", ".en.forced.srt"},
+		{LanguageKeep, "Movie.English.SDH.forced.srt", ".en.forced.sdh.srt"},
+		{LanguageKeep, "Movie.eng.sdh.srt", ".eng.sdh.srt"},
+		{LanguageISO6391, "Movie.eng.sdh.srt", ".en.sdh.srt"},
+		{LanguageISO6392, "Movie.forced.en.default.ass", ".eng.default.forced.ass"},
+		{LanguageISO6391, "Movie.cc.srt", ".sdh.srt"},
+	}
+	for _, tc := range tests {
+		SetLanguageForm(tc.form)
+		if got := ExtractSubtitleSuffix(tc.in); got != tc.want {
+			t.Errorf("ExtractSubtitleSuffix(%q) with form %d = %q, want %q", tc.in, tc.form, got, tc.want)
+		}
+	}
+}
+
+func TestCompanionSuffixesSubtitleFlags(t *testing.T) {
+	t.Parallel()
+	got := CompanionSuffixes("Movie.2020.English.forced.srt")
+	want := []string{".srt", ".forced.srt", ".English.forced.srt"}
+	if !slices.Equal(got, want) {
+		t.Errorf("CompanionSuffixes() = %q, want %q", got, want)
+	}
+	if got := NormalizeCompanionSuffix(".English.forced.srt"); got != ".en.forced.srt" {
+		t.Errorf("NormalizeCompanionSuffix() = %q, want %q", got, ".en.forced.srt")
+	}
+}
+
+func TestGuessSubtitleTags(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in   string
+		want SubtitleTags
+	}{
+		{"2_English.srt", SubtitleTags{Language: "English"}},
+		{"3_eng.srt", SubtitleTags{Language: "eng"}},
+		{"04 - English (SDH).srt", SubtitleTags{Language: "English", SDH: true}},
+		{"Forced.French.srt", SubtitleTags{Language: "French", Forced: true}},
+		{"Movie.2020.de.forced.srt", SubtitleTags{Language: "de", Forced: true}},
+		{"Movie.2020.srt", SubtitleTags{}},
+	}
+	for _, tc := range tests {
+		if got := GuessSubtitleTags(tc.in); got != tc.want {
+			t.Errorf("GuessSubtitleTags(%q) = %+v, want %+v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestSubtitleTrack(t *testing.T) {
+	t.Parallel()
+	for in, want := range map[string]string{"3_English.srt": "3", "03 - eng.srt": "03", "English.srt": "", "Movie.2020.en.srt": ""} {
+		if got := SubtitleTrack(in); got != want {
+			t.Errorf("SubtitleTrack(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
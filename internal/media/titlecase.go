@@ -0,0 +1,175 @@
+package media
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TitleCaseStyle selects how FormatShowName cases titles.
+type TitleCaseStyle int
+
+const (
+	TitleCaseOff  TitleCaseStyle = iota // Titles keep the case of the release name
+	TitleCaseOn                         // "The Office (US)", "Marvel's Agents of S.H.I.E.L.D."
+	TitleCaseSort                       // Title case with the leading article moved: "Office, The (US)"
+)
+
+var (
+	// initialismRe matches dotted initialisms like "S.W.A.T." or "E.T", whose dots
+	// are not separators. Each letter must stand alone so "The.A.Team" is not one.
+	initialismRe = regexp.MustCompile(`\b[A-Za-z](?:\.[A-Za-z]\b)+\.?`)
+
+	// romanNumeralRe matches the roman numerals used in sequel titles (II to XXXIX).
+	romanNumeralRe = regexp.MustCompile(`(?i)^X{0,3}(?:IX|IV|V?I{0,3})$`)
+
+	// smallWords stay lower case inside a title.
+	smallWords = map[string]bool{
+		"a": true, "an": true, "and": true, "as": true, "at": true, "but": true, "by": true,
+		"for": true, "from": true, "in": true, "into": true, "nor": true,
+		"of": true, "on": true, "or": true, "per": true, "the": true, "to": true, "via": true,
+		"vs": true, "with": true,
+	}
+
+	// acronyms keep their capitals wherever they appear.
+	acronyms = map[string]string{}
+
+	// countryCodes are the country qualifiers written in parentheses after a title
+	// that exists in several countries: "The Office (US)".
+	countryCodes = map[string]bool{"US": true, "UK": true, "AU": true, "NZ": true, "CA": true, "IE": true}
+
+	// wordCodes are country codes that are also common words ("This Is Us"); they
+	// are only taken for a country when written as one.
+	wordCodes = map[string]bool{"US": true, "CA": true}
+
+	// articles are moved to the end of a title by TitleCaseSort.
+	articles = map[string]bool{"the": true, "a": true, "an": true}
+)
+
+func init() {
+	for _, a := range []string{
+		"ABC", "BBC", "CIA", "CSI", "DC", "FBI", "HBO", "JAG", "MTV", "NASA", "NCIS", "NYPD",
+		"SVU", "SWAT", "TV", "UFO", "USA", "WWE", "WWII",
+	} {
+		acronyms[strings.ToLower(a)] = a
+	}
+}
+
+// titleCaseStyle is the active style, set once at startup by SetTitleCaseStyle.
+var titleCaseStyle = TitleCaseOff
+
+// SetTitleCaseStyle selects how FormatShowName cases titles. It is not safe to
+// call while names are being formatted.
+func SetTitleCaseStyle(s TitleCaseStyle) { titleCaseStyle = s }
+
+// ParseTitleCaseStyle resolves the --title-case values off, on and sort.
+func ParseTitleCaseStyle(s string) (TitleCaseStyle, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "off", "keep":
+		return TitleCaseOff, nil
+	case "on", "title":
+		return TitleCaseOn, nil
+	case "sort":
+		return TitleCaseSort, nil
+	}
+	return TitleCaseOff, fmt.Errorf("unknown title case %q (want off, on or sort)", s)
+}
+
+// replaceSeparators turns the dots, dashes and underscores of a release name
+// into spaces. With title casing on, dotted initialisms keep their dots.
+func replaceSeparators(s string) string {
+	r := strings.NewReplacer(".", " ", "-", " ", "_", " ")
+	if titleCaseStyle == TitleCaseOff {
+		return r.Replace(s)
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range initialismRe.FindAllStringIndex(s, -1) {
+		b.WriteString(r.Replace(s[last:loc[0]]))
+		b.WriteString(" " + strings.ToUpper(strings.TrimSuffix(s[loc[0]:loc[1]], ".")) + ". ")
+		last = loc[1]
+	}
+	b.WriteString(r.Replace(s[last:]))
+	return b.String()
+}
+
+// applyTitleCase cases a cleaned up, space separated title in the active style.
+// Small words are lower case except first and last, acronyms, roman numerals and
+// dotted initialisms are upper case, and a trailing country code is put in
+// parentheses (see isCountryQualifier). Words written in capitals inside a mixed
+// case title are taken for acronyms while a title written in a single case is
+// cased from scratch, so "Between.Us" keeps its last word.
+func applyTitleCase(title string) string {
+	if titleCaseStyle == TitleCaseOff || title == "" {
+		return title
+	}
+	words := strings.Fields(title)
+	mixed := strings.ToLower(title) != title && strings.ToUpper(title) != title
+	qualifier := ""
+	if n := len(words); n > 1 {
+		if code, ok := isCountryQualifier(words[n-1], n-1, mixed); ok {
+			qualifier = "(" + code + ")"
+			words = words[:n-1]
+		}
+	}
+	for i, w := range words {
+		words[i] = caseWord(w, i == 0 || i == len(words)-1 || strings.HasSuffix(words[i-1], ":"), mixed)
+	}
+	if titleCaseStyle == TitleCaseSort && len(words) > 1 && articles[strings.ToLower(words[0])] {
+		words = append(words[1:], words[0])
+		words[len(words)-2] += ","
+	}
+	if qualifier != "" {
+		words = append(words, qualifier)
+	}
+	return strings.Join(words, " ")
+}
+
+// isCountryQualifier reports whether word, which ends a title following `others`
+// words, is a country code written after the title, and returns the code. The
+// code must be parenthesized, written in capitals inside a mixed case title
+// ("The.Office.US"), or follow at least two words without being a common word
+// ("the.office.uk", but not "this.is.us").
+func isCountryQualifier(word string, others int, mixed bool) (string, bool) {
+	bare := strings.Trim(word, "()[]")
+	code := strings.ToUpper(bare)
+	switch {
+	case !countryCodes[code]:
+		return "", false
+	case bare != word, mixed && bare == code:
+		return code, true
+	}
+	return code, others > 1 && !wordCodes[code]
+}
+
+// caseWord cases a single word of a title. edge is set for the first and last
+// word and words after a colon, which are capitalized even when small.
+func caseWord(w string, edge, mixed bool) string {
+	start := strings.IndexFunc(w, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) })
+	if start < 0 {
+		return w
+	}
+	end := strings.LastIndexFunc(w, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) })
+	_, size := utf8.DecodeRuneInString(w[end:])
+	end += size
+	prefix, core, suffix := w[:start], w[start:end], w[end:]
+	lower := strings.ToLower(core)
+	switch {
+	case strings.Contains(core, ".") && initialismRe.MatchString(core):
+		core = strings.ToUpper(core)
+	case acronyms[lower] != "":
+		core = acronyms[lower]
+	case len(core) > 1 && romanNumeralRe.MatchString(core):
+		core = strings.ToUpper(core)
+	case mixed && len(core) > 1 && core == strings.ToUpper(core) && strings.ToLower(core) != core:
+		// Kept as written: an acronym in a mixed case title.
+	case smallWords[lower] && !edge:
+		core = lower
+	case core == lower || core == strings.ToUpper(core):
+		r, size := utf8.DecodeRuneInString(lower)
+		core = string(unicode.ToUpper(r)) + lower[size:]
+	}
+	return prefix + core + suffix
+}
@@ -0,0 +1,75 @@
+package media
+
+import "testing"
+
+func TestFormatShowNameTitleCase(t *testing.T) {
+	SetTitleCaseStyle(TitleCaseOn)
+	defer SetTitleCaseStyle(TitleCaseOff)
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"the.office.uk", "The Office (UK)"},
+		{"The.Office.US", "The Office (US)"},
+		{"The.Office.US.2005", "The Office (US) (2005)"},
+		{"this.is.us", "This Is Us"},
+		{"this.is.us.2016", "This Is Us (2016)"},
+		{"S.W.A.T.2017", "S.W.A.T. (2017)"},
+		{"marvels.agents.of.s.h.i.e.l.d.2013.1080p", "Marvels Agents of S.H.I.E.L.D. (2013)"},
+		{"E.T.the.Extra-Terrestrial.1982", "E.T. the Extra Terrestrial (1982)"},
+		{"THE.LORD.OF.THE.RINGS.THE.RETURN.OF.THE.KING.2003", "The Lord of the Rings the Return of the King (2003)"},
+		{"ncis.los.angeles", "NCIS Los Angeles"},
+		{"rocky.ii.1979", "Rocky II (1979)"},
+		{"A.Few.Good.Men.1992", "A Few Good Men (1992)"},
+		{"A.I.Artificial.Intelligence.2001", "A.I. Artificial Intelligence (2001)"},
+		{"catch.me.if.you.can", "Catch Me If You Can"},
+		{"Between.Us.2016", "Between Us (2016)"},
+		{"Gone.in.60.Seconds", "Gone in 60 Seconds"},
+		{"What.We.Do.in.the.Shadows.FX", "What We Do in the Shadows FX"},
+		{"MacGyver.2016", "MacGyver (2016)"},
+		{"The Office (US)", "The Office (US)"},
+	}
+	for _, tc := range tests {
+		if got := FormatShowName(tc.in); got != tc.want {
+			t.Errorf("FormatShowName(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestFormatShowNameSortArticles(t *testing.T) {
+	SetTitleCaseStyle(TitleCaseSort)
+	defer SetTitleCaseStyle(TitleCaseOff)
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"The.Office.US.2005", "Office, The (US) (2005)"},
+		{"A.Quiet.Place.2018", "Quiet Place, A (2018)"},
+		{"The.2019", "The (2019)"},
+		{"Office, The (US) (2005)", "Office, The (US) (2005)"},
+		{"Theodore.Rex.1995", "Theodore Rex (1995)"},
+	}
+	for _, tc := range tests {
+		if got := FormatShowName(tc.in); got != tc.want {
+			t.Errorf("FormatShowName(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestFormatShowNameKeepsCaseByDefault(t *testing.T) {
+	if got := FormatShowName("the.office.us"); got != "the office us" {
+		t.Errorf("FormatShowName(%q) = %q, want %q", "the.office.us", got, "the office us")
+	}
+}
+
+func TestParseTitleCaseStyle(t *testing.T) {
+	t.Parallel()
+	for in, want := range map[string]TitleCaseStyle{"": TitleCaseOff, "off": TitleCaseOff, "On": TitleCaseOn, "sort": TitleCaseSort} {
+		if got, err := ParseTitleCaseStyle(in); err != nil || got != want {
+			t.Errorf("ParseTitleCaseStyle(%q) = (%d, %v), want %d", in, got, err, want)
+		}
+	}
+	if _, err := ParseTitleCaseStyle("upper"); err == nil {
+		t.Error("ParseTitleCaseStyle(\"upper\") error = nil, want error")
+	}
+}
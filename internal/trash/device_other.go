@@ -0,0 +1,12 @@
+//go:build !unix
+
+package trash
+
+import "os"
+
+// deviceOf cannot distinguish devices on this platform, so every path is
+// treated as living alongside the home trash.
+func deviceOf(path string) (uint64, error) {
+	_, err := os.Stat(path)
+	return 0, err
+}
@@ -0,0 +1,89 @@
+package trash
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// Move renames src to dst, falling back to copy-then-delete when they live
+// on different filesystems (e.g. a download disk and a library disk). Directory
+// trees are copied recursively, preserving permissions and modification times.
+func Move(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	return moveAcross(src, dst)
+}
+
+// moveAcross copies src to dst then removes src, leaving the source
+// authoritative when the copy fails.
+func moveAcross(src, dst string) error {
+	if err := Copy(src, dst); err != nil {
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+// Copy copies the file or directory tree at src to dst, preserving permissions
+// and modification times. It refuses an existing dst, so when the copy fails
+// everything at dst was created here and is removed again.
+func Copy(src, dst string) error {
+	if _, err := os.Lstat(dst); err == nil {
+		return &fs.PathError{Op: "copy", Path: dst, Err: fs.ErrExist}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if err := copyTree(src, dst); err != nil {
+		os.RemoveAll(dst)
+		return err
+	}
+	return nil
+}
+
+// copyTree copies the file or directory at src to dst.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return os.Mkdir(target, info.Mode().Perm())
+		}
+		if err := copyFile(path, target, info.Mode().Perm()); err != nil {
+			return err
+		}
+		return os.Chtimes(target, info.ModTime(), info.ModTime())
+	})
+}
+
+// copyFile copies a regular file, refusing to overwrite an existing target.
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
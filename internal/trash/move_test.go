@@ -0,0 +1,74 @@
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyTree(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "src")
+	os.MkdirAll(filepath.Join(src, "Subs"), 0755)
+	os.WriteFile(filepath.Join(src, "movie.mkv"), []byte("video"), 0644)
+	os.WriteFile(filepath.Join(src, "Subs", "en.srt"), []byte("subs"), 0600)
+
+	dst := filepath.Join(tmp, "dst")
+	if err := copyTree(src, dst); err != nil {
+		t.Fatalf("copyTree() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dst, "Subs", "en.srt"))
+	if err != nil || string(data) != "subs" {
+		t.Errorf("copyTree() Subs/en.srt = %q, %v; want %q", data, err, "subs")
+	}
+	if info, err := os.Stat(filepath.Join(dst, "Subs", "en.srt")); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("copyTree() did not preserve permissions: %v", info.Mode())
+	}
+	if err := copyTree(src, dst); err == nil {
+		t.Errorf("copyTree() onto an existing tree succeeded, want error")
+	}
+}
+
+func TestMove(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "a.mkv")
+	os.WriteFile(src, []byte("video"), 0644)
+	if err := Move(src, filepath.Join(tmp, "b.mkv")); err != nil {
+		t.Fatalf("Move() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmp, "b.mkv")); err != nil {
+		t.Errorf("Move() destination missing: %v", err)
+	}
+}
+
+func TestMoveAcross(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "src")
+	os.MkdirAll(filepath.Join(src, "Subs"), 0755)
+	os.WriteFile(filepath.Join(src, "Subs", "en.srt"), []byte("subs"), 0644)
+
+	// An existing destination is refused and left untouched
+	dst := filepath.Join(tmp, "dst")
+	os.MkdirAll(filepath.Join(dst, "Subs"), 0755)
+	os.WriteFile(filepath.Join(dst, "keep.mkv"), []byte("keep"), 0644)
+	if err := moveAcross(src, dst); err == nil {
+		t.Errorf("moveAcross() onto an existing directory succeeded, want error")
+	}
+	if data, err := os.ReadFile(filepath.Join(dst, "keep.mkv")); err != nil || string(data) != "keep" {
+		t.Errorf("moveAcross() touched the existing destination: %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(src, "Subs", "en.srt")); err != nil {
+		t.Errorf("moveAcross() removed the source after failing: %v", err)
+	}
+
+	moved := filepath.Join(tmp, "moved")
+	if err := moveAcross(src, moved); err != nil {
+		t.Fatalf("moveAcross() error = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(moved, "Subs", "en.srt")); err != nil || string(data) != "subs" {
+		t.Errorf("moveAcross() Subs/en.srt = %q, %v; want subs", data, err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("moveAcross() left the source in place")
+	}
+}
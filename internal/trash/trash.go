@@ -0,0 +1,203 @@
+// Package trash disposes of files removed during a rename run. By default files
+// are moved to the freedesktop.org trash so they can be restored; a quarantine
+// directory or permanent deletion can be selected instead.
+package trash
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bin selects how Remove disposes of a path. The zero value moves files to the
+// XDG trash, falling back to a per-mount .Trash-$UID directory when the file
+// lives on a different device than the home trash.
+type Bin struct {
+	Quarantine string // When set, files are moved into this directory instead of the trash
+	Hard       bool   // Permanently delete instead of trashing (previous behavior)
+}
+
+// Remove disposes of path according to the bin configuration.
+func (b Bin) Remove(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	switch {
+	case b.Hard:
+		if info.IsDir() {
+			return os.RemoveAll(path)
+		}
+		return os.Remove(path)
+	case b.Quarantine != "":
+		if err := os.MkdirAll(b.Quarantine, 0755); err != nil {
+			return err
+		}
+		_, err := moveUnique(path, b.Quarantine)
+		return err
+	}
+	return toTrash(path)
+}
+
+// toTrash implements the freedesktop.org trash specification: a .trashinfo
+// record is written to info/ before the file itself is moved into files/.
+func toTrash(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	dir, top, err := trashDirFor(abs)
+	if err != nil {
+		return err
+	}
+	if dir, top, err = usableTrashDir(dir, top); err != nil {
+		return err
+	}
+	filesDir := filepath.Join(dir, "files")
+	infoDir := filepath.Join(dir, "info")
+
+	// Paths in per-mount trash directories are recorded relative to the mount.
+	recorded := abs
+	if top != "" {
+		if rel, err := filepath.Rel(top, abs); err == nil {
+			recorded = rel
+		}
+	}
+
+	base := filepath.Base(abs)
+	for i := 1; ; i++ {
+		name := base
+		if i > 1 {
+			name = base + "." + strconv.Itoa(i)
+		}
+		infoPath := filepath.Join(infoDir, name+".trashinfo")
+		f, err := os.OpenFile(infoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(f, "[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+			(&url.URL{Path: recorded}).EscapedPath(), time.Now().Format("2006-01-02T15:04:05"))
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil {
+			err = Move(abs, filepath.Join(filesDir, name))
+		}
+		if err != nil {
+			os.Remove(infoPath)
+		}
+		return err
+	}
+}
+
+// trashDirFor returns the trash directory for abs and, for per-mount trashes,
+// the mount's top directory.
+func trashDirFor(abs string) (string, string, error) {
+	home, err := homeTrash()
+	if err != nil {
+		return "", "", err
+	}
+	if err := os.MkdirAll(home, 0700); err != nil {
+		return "", "", err
+	}
+	fileDev, err := deviceOf(abs)
+	if err != nil {
+		return "", "", err
+	}
+	homeDev, err := deviceOf(home)
+	if err != nil {
+		return "", "", err
+	}
+	if fileDev == homeDev {
+		return home, "", nil
+	}
+
+	top := mountTop(abs, fileDev)
+	uid := strconv.Itoa(os.Getuid())
+	// Prefer an administrator-provided $topdir/.Trash with the sticky bit set.
+	shared := filepath.Join(top, ".Trash")
+	if info, err := os.Lstat(shared); err == nil && info.IsDir() && info.Mode()&os.ModeSticky != 0 {
+		return filepath.Join(shared, uid), top, nil
+	}
+	return filepath.Join(top, ".Trash-"+uid), top, nil
+}
+
+// usableTrashDir creates the files/ and info/ directories of the trash dir.
+// When a per-mount trash cannot be created (a read-only or foreign mount top) the
+// home trash is used instead, the file then being copied across devices.
+func usableTrashDir(dir, top string) (string, string, error) {
+	err := makeTrashDir(dir)
+	if err == nil || top == "" {
+		return dir, top, err
+	}
+	home, herr := homeTrash()
+	if herr == nil {
+		herr = makeTrashDir(home)
+	}
+	if herr != nil {
+		return "", "", fmt.Errorf("no usable trash: %s: %w; home trash: %v", dir, err, herr)
+	}
+	return home, "", nil
+}
+
+// makeTrashDir creates the files/ and info/ directories of a trash directory.
+func makeTrashDir(dir string) error {
+	for _, d := range []string{"files", "info"} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0700); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// homeTrash returns $XDG_DATA_HOME/Trash, defaulting to ~/.local/share/Trash.
+func homeTrash() (string, error) {
+	if data := os.Getenv("XDG_DATA_HOME"); data != "" {
+		return filepath.Join(data, "Trash"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "Trash"), nil
+}
+
+// mountTop walks up from abs while the parent stays on the same device.
+func mountTop(abs string, dev uint64) string {
+	dir := filepath.Dir(abs)
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		if d, err := deviceOf(parent); err != nil || d != dev {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// moveUnique moves path into dir, appending a counter when the name is taken.
+// The quarantine directory may live on another filesystem.
+func moveUnique(path, dir string) (string, error) {
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	for i := 1; ; i++ {
+		target := filepath.Join(dir, base)
+		if i > 1 {
+			target = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", stem, i, ext))
+		}
+		if _, err := os.Lstat(target); err == nil {
+			continue
+		}
+		return target, Move(path, target)
+	}
+}
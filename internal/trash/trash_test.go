@@ -0,0 +1,125 @@
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBinRemove_Trash(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data"))
+	src := filepath.Join(tmp, "movie name.nfo")
+	if err := os.WriteFile(src, []byte("nfo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := (Bin{}).Remove(src); err != nil {
+		t.Fatalf("Bin{}.Remove() error = %v", err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("Bin{}.Remove() left source in place")
+	}
+	trashed := filepath.Join(tmp, "data", "Trash", "files", "movie name.nfo")
+	if data, err := os.ReadFile(trashed); err != nil || string(data) != "nfo" {
+		t.Errorf("Bin{}.Remove() trashed file = %q (%v), want nfo", data, err)
+	}
+	info, err := os.ReadFile(filepath.Join(tmp, "data", "Trash", "info", "movie name.nfo.trashinfo"))
+	if err != nil {
+		t.Fatalf("Bin{}.Remove() missing trashinfo: %v", err)
+	}
+	for _, want := range []string{"[Trash Info]", "Path=" + filepath.ToSlash(tmp) + "/movie%20name.nfo", "DeletionDate="} {
+		if !strings.Contains(string(info), want) {
+			t.Errorf("trashinfo = %q, missing %q", info, want)
+		}
+	}
+
+	// A second file with the same name gets a unique trash entry
+	os.WriteFile(src, []byte("second"), 0644)
+	if err := (Bin{}).Remove(src); err != nil {
+		t.Fatalf("Bin{}.Remove(duplicate) error = %v", err)
+	}
+	if data, _ := os.ReadFile(trashed + ".2"); string(data) != "second" {
+		t.Errorf("Bin{}.Remove(duplicate) = %q, want second", data)
+	}
+}
+
+func TestBinRemove_Quarantine(t *testing.T) {
+	tmp := t.TempDir()
+	q := filepath.Join(tmp, "quarantine")
+	for i := 0; i < 2; i++ {
+		src := filepath.Join(tmp, "poster.jpg")
+		os.WriteFile(src, []byte{byte(i)}, 0644)
+		if err := (Bin{Quarantine: q}).Remove(src); err != nil {
+			t.Fatalf("Bin{Quarantine}.Remove() error = %v", err)
+		}
+	}
+	for _, name := range []string{"poster.jpg", "poster (2).jpg"} {
+		if _, err := os.Stat(filepath.Join(q, name)); err != nil {
+			t.Errorf("Bin{Quarantine}.Remove() missing %s: %v", name, err)
+		}
+	}
+}
+
+func TestBinRemove_Hard(t *testing.T) {
+	tmp := t.TempDir()
+	file := filepath.Join(tmp, "a.nfo")
+	dir := filepath.Join(tmp, "Screens")
+	os.WriteFile(file, nil, 0644)
+	os.MkdirAll(filepath.Join(dir, "nested"), 0755)
+	for _, p := range []string{file, dir} {
+		if err := (Bin{Hard: true}).Remove(p); err != nil {
+			t.Errorf("Bin{Hard}.Remove(%s) error = %v", p, err)
+		}
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("Bin{Hard}.Remove(%s) left path in place", p)
+		}
+	}
+	if err := (Bin{Hard: true}).Remove(file); err == nil {
+		t.Errorf("Bin{Hard}.Remove(missing) error = nil, want error")
+	}
+}
+
+func TestMountTop(t *testing.T) {
+	tmp := t.TempDir()
+	nested := filepath.Join(tmp, "a", "b")
+	os.MkdirAll(nested, 0755)
+	dev, err := deviceOf(nested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	top := mountTop(filepath.Join(nested, "file"), dev)
+	if !strings.HasPrefix(nested, top) {
+		t.Errorf("mountTop(%s) = %s, want an ancestor", nested, top)
+	}
+}
+
+func TestUsableTrashDir(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data"))
+	home := filepath.Join(tmp, "data", "Trash")
+
+	mount := filepath.Join(tmp, "mount", ".Trash-1000")
+	if dir, top, err := usableTrashDir(mount, filepath.Join(tmp, "mount")); err != nil || dir != mount || top == "" {
+		t.Errorf("usableTrashDir(per-mount) = (%s,%s,%v), want the mount trash", dir, top, err)
+	}
+
+	// A per-mount trash that cannot be created falls back to the home trash
+	blocked := filepath.Join(tmp, "blocked")
+	os.MkdirAll(blocked, 0755)
+	os.WriteFile(filepath.Join(blocked, ".Trash-1000"), nil, 0644)
+	dir, top, err := usableTrashDir(filepath.Join(blocked, ".Trash-1000"), blocked)
+	if err != nil || dir != home || top != "" {
+		t.Errorf("usableTrashDir(blocked) = (%s,%s,%v), want (%s,,<nil>)", dir, top, err, home)
+	}
+	if _, err := os.Stat(filepath.Join(home, "files")); err != nil {
+		t.Errorf("usableTrashDir(blocked) did not create the home trash: %v", err)
+	}
+
+	// Without a usable home trash either it refuses with an error
+	os.WriteFile(filepath.Join(tmp, "nodata"), nil, 0644)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "nodata"))
+	if _, _, err := usableTrashDir(filepath.Join(blocked, ".Trash-1000"), blocked); err == nil {
+		t.Errorf("usableTrashDir(no trash) error = nil, want error")
+	}
+}
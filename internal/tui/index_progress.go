@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/Digital-Shane/title-tidy/internal/ignore"
 	"github.com/Digital-Shane/treeview"
 	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
@@ -53,6 +54,7 @@ type IndexConfig struct {
 	MaxDepth    int
 	IncludeDirs bool
 	Filter      func(treeview.FileInfo) bool
+	Ignore      *ignore.Matcher // entries matched by ignore files are never indexed
 }
 
 // NewIndexProgressModel creates a model and pre computes root entry count.
@@ -89,6 +91,9 @@ func (m *IndexProgressModel) buildTreeAsync() {
 		treeview.WithMaxDepth[treeview.FileInfo](m.cfg.MaxDepth),
 		treeview.WithTraversalCap[treeview.FileInfo](2000000),
 		treeview.WithFilterFunc(func(fi treeview.FileInfo) bool {
+			if m.cfg.Ignore != nil && m.cfg.Ignore.Match(fi.Path, fi.IsDir()) {
+				return false
+			}
 			if m.cfg.Filter != nil {
 				return m.cfg.Filter(fi)
 			}
@@ -0,0 +1,9 @@
+package tui
+
+import "github.com/Digital-Shane/title-tidy/internal/trash"
+
+// MovePath renames src to dst, falling back to copy-then-delete when they live
+// on different filesystems (e.g. a download disk and a library disk).
+func MovePath(src, dst string) error {
+	return trash.Move(src, dst)
+}
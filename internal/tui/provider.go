@@ -38,6 +38,7 @@ var (
 		"episode":   "🎬",
 		"movie":     "🎬",
 		"moviefile": "🎥",
+		"extra":     "🎞",
 		"default":   "📄",
 	}
 
@@ -52,6 +53,7 @@ var (
 		"episode":   "[E]",
 		"movie":     "[M]",
 		"moviefile": "[F]",
+		"extra":     "[X]",
 		"default":   "[ ]",
 	}
 )
@@ -152,6 +154,7 @@ func CreateRenameProvider() *treeview.DefaultNodeProvider[treeview.FileInfo] {
 		}
 		return statusNoneType(core.MediaMovieFile)(n)
 	}, iconSet["moviefile"])
+	extraIconRule := treeview.WithIconRule(statusNoneType(core.MediaExtra), iconSet["extra"])
 	defaultIconRule := treeview.WithDefaultIcon[treeview.FileInfo](iconSet["default"])
 
 	// Style rules (most specific first)
@@ -171,7 +174,7 @@ func CreateRenameProvider() *treeview.DefaultNodeProvider[treeview.FileInfo] {
 		lipgloss.NewStyle().Foreground(colorBackground).Background(colorPrimary),
 	)
 	movieStyleRule := treeview.WithStyleRule(
-		typeIs(core.MediaMovie),
+		metaRule(func(mm *core.MediaMeta) bool { return mm.Type == core.MediaMovie || mm.Type == core.MediaCollection }),
 		lipgloss.NewStyle().Foreground(colorPrimary).Bold(true),
 		lipgloss.NewStyle().Foreground(colorBackground).Bold(true).Background(colorSecondary).PaddingRight(1),
 	)
@@ -212,7 +215,7 @@ func CreateRenameProvider() *treeview.DefaultNodeProvider[treeview.FileInfo] {
 	return treeview.NewDefaultNodeProvider(
 		// Icon rules (order matters - most specific first)
 		deletionSuccessIconRule, deletionErrorIconRule, markedForDeletionIconRule,
-		successIconRule, errorIconRule, virtualDirIconRule, showIconRule, seasonIconRule, episodeIconRule, movieIconRule, movieFileIconRule, defaultIconRule,
+		successIconRule, errorIconRule, virtualDirIconRule, showIconRule, seasonIconRule, episodeIconRule, movieIconRule, movieFileIconRule, extraIconRule, defaultIconRule,
 		// Style rules (order matters - most specific first)
 		deletionSuccessStyleRule, markedForDeletionStyleRule, successStyleRule, errorStyleRule, showStyleRule, seasonStyleRule, episodeStyleRule, movieStyleRule, movieFileStyleRule, defaultStyleRule,
 		// Formatter
@@ -223,12 +226,47 @@ func CreateRenameProvider() *treeview.DefaultNodeProvider[treeview.FileInfo] {
 // RenameFormatter produces the display label for a node during visualization.
 //
 //   - If no metadata or no proposed NewName exists, the original name is returned unchanged.
+//   - Release samples kept on disk get a [sample] suffix, DVD and Blu-ray folders a [disc] suffix.
 //   - On success, only the new name is shown (keeps the tree clean post‑apply).
 //   - On error, the original name plus the error message are shown.
+//   - For collisions resolved by the upgrade policy, the decision and quality comparison are appended.
+//   - For merges into an existing directory, a [MERGE] prefix is prepended.
 //   - For virtual directory creation, a [NEW] prefix is prepended to the proposed name.
 //   - If the new name equals the original, the original is shown.
 //   - Otherwise: "<new> ← <old>" conveys the pending rename mapping.
+//
+// Subtitles whose language was detected from their contents get "[detected: en]"
+// appended until renamed, subtitles converted to UTF-8 get the conversion and its
+// outcome appended, and entries classified by auto mode get the chosen command
+// appended, e.g. "[movies]".
 func RenameFormatter(node *treeview.Node[treeview.FileInfo]) (string, bool) {
+	label, ok := renameLabel(node)
+	mm := core.GetMeta(node)
+	if mm != nil && mm.DetectedLanguage != "" && mm.RenameStatus == core.RenameStatusNone {
+		label = fmt.Sprintf("%s [detected: %s]", label, mm.DetectedLanguage)
+	}
+	if mm != nil && mm.ConvertFrom != "" && !mm.MarkedForDeletion {
+		label += convertLabel(mm)
+	}
+	if mm != nil && mm.Mode != "" {
+		label = fmt.Sprintf("%s [%s]", label, mm.Mode)
+	}
+	return label, ok
+}
+
+// convertLabel describes the encoding conversion planned or done for a subtitle.
+func convertLabel(mm *core.MediaMeta) string {
+	switch mm.ConvertStatus {
+	case core.RenameStatusSuccess:
+		return " [utf-8]"
+	case core.RenameStatusError:
+		return fmt.Sprintf(" [utf-8 failed: %s]", mm.ConvertError)
+	}
+	return fmt.Sprintf(" [%s → utf-8]", mm.ConvertFrom)
+}
+
+// renameLabel implements RenameFormatter apart from the auto mode tag.
+func renameLabel(node *treeview.Node[treeview.FileInfo]) (string, bool) {
 	mm := core.GetMeta(node)
 	if mm == nil {
 		return node.Name(), true
@@ -242,6 +280,15 @@ func RenameFormatter(node *treeview.Node[treeview.FileInfo]) (string, bool) {
 		return node.Name(), true
 	}
 
+	if mm.Type == core.MediaSample {
+		return node.Name() + " [sample]", true
+	}
+	if mm.Type == core.MediaDisc {
+		return node.Name() + " [disc]", true
+	}
+	if mm.Type == core.MediaFlattened {
+		return node.Name() + " [flatten]", true
+	}
 	if mm.NewName == "" {
 		// no proposed rename
 		return node.Name(), true
@@ -253,12 +300,28 @@ func RenameFormatter(node *treeview.Node[treeview.FileInfo]) (string, bool) {
 	case core.RenameStatusError:
 		return fmt.Sprintf("%s: %s", node.Name(), mm.RenameError), true
 	}
+	// Collision with an existing file resolved by the upgrade policy
+	switch mm.Upgrade {
+	case core.UpgradeReplaceExisting:
+		return fmt.Sprintf("%s ← %s [upgrade: %s]", mm.NewName, node.Name(), mm.UpgradeReason), true
+	case core.UpgradeDiscardIncoming:
+		return fmt.Sprintf("%s [keep existing %s: %s]", node.Name(), mm.NewName, mm.UpgradeReason), true
+	case core.UpgradeKeepIncoming:
+		return fmt.Sprintf("%s [left as is, keep existing %s: %s]", node.Name(), mm.NewName, mm.UpgradeReason), true
+	}
+	// Directory merging into an existing destination
+	if mm.MergeIntoExisting {
+		if mm.NeedsDirectory || mm.NewName == node.Name() {
+			return "[MERGE] " + mm.NewName, true
+		}
+		return fmt.Sprintf("[MERGE] %s ← %s", mm.NewName, node.Name()), true
+	}
 	// Virtual / directory creation
 	if mm.NeedsDirectory {
 		return "[NEW] " + mm.NewName, true
 	}
 	// Unchanged name, keep original
-	if mm.NewName == node.Name() {
+	if mm.NewName == node.Name() && mm.DestDir == "" {
 		return node.Name(), true
 	}
 	return fmt.Sprintf("%s ← %s", mm.NewName, node.Name()), true
@@ -119,6 +119,36 @@ func TestRenameFormatter(t *testing.T) {
 		{"Virtual", "oldDir", true, func(mm *core.MediaMeta) { mm.NewName = "Movie Name"; mm.NeedsDirectory = true }, "[NEW] Movie Name"},
 		{"Same", "same", false, func(mm *core.MediaMeta) { mm.NewName = "same" }, "same"},
 		{"Mapping", "oldname", false, func(mm *core.MediaMeta) { mm.NewName = "New Name" }, "New Name ← oldname"},
+		{"Merge", "Show.2024", true, func(mm *core.MediaMeta) { mm.NewName = "Show (2024)"; mm.MergeIntoExisting = true }, "[MERGE] Show (2024) ← Show.2024"},
+		{"Upgrade", "Show.S01E02.1080p.mkv", false, func(mm *core.MediaMeta) {
+			mm.NewName = "S01E02.mkv"
+			mm.Upgrade = core.UpgradeReplaceExisting
+			mm.UpgradeReason = "1080p vs 720p"
+		}, "S01E02.mkv ← Show.S01E02.1080p.mkv [upgrade: 1080p vs 720p]"},
+		{"KeepExisting", "Show.S01E02.480p.mkv", false, func(mm *core.MediaMeta) {
+			mm.NewName = "S01E02.mkv"
+			mm.Upgrade = core.UpgradeDiscardIncoming
+			mm.UpgradeReason = "480p vs 720p"
+		}, "Show.S01E02.480p.mkv [keep existing S01E02.mkv: 480p vs 720p]"},
+		{"MergeVirtual", "movie", true, func(mm *core.MediaMeta) {
+			mm.NewName = "Movie (2020)"
+			mm.NeedsDirectory = true
+			mm.MergeIntoExisting = true
+		}, "[MERGE] Movie (2020)"},
+		{"DetectedLanguage", "movie.srt", false, func(mm *core.MediaMeta) {
+			mm.NewName = "Movie (2020).fr.srt"
+			mm.DetectedLanguage = "fr"
+		}, "Movie (2020).fr.srt ← movie.srt [detected: fr]"},
+		{"Disc", "VIDEO_TS", true, func(mm *core.MediaMeta) { mm.Type = core.MediaDisc }, "VIDEO_TS [disc]"},
+		{"ConvertPending", "movie.ru.srt", false, func(mm *core.MediaMeta) {
+			mm.NewName = "Movie (2020).ru.srt"
+			mm.ConvertFrom = "windows-1251"
+		}, "Movie (2020).ru.srt ← movie.ru.srt [windows-1251 → utf-8]"},
+		{"ConvertFailed", "movie.srt", false, func(mm *core.MediaMeta) {
+			mm.ConvertFrom = "windows-1252"
+			mm.ConvertStatus = core.RenameStatusError
+			mm.ConvertError = "backup: file exists"
+		}, "movie.srt [utf-8 failed: backup: file exists]"},
 	}
 	for _, tc := range cases {
 		n := testNode(tc.nodeName, tc.isDir)
@@ -5,14 +5,17 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/Digital-Shane/title-tidy/internal/charset"
 	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/trash"
 	"github.com/Digital-Shane/treeview"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 // RenameCompleteMsg is emitted once performRenames finishes walking the tree.
-type RenameCompleteMsg struct{ successCount, errorCount int }
+type RenameCompleteMsg struct{ successCount, errorCount, cleanedCount int }
 
 // SuccessCount returns the number of successful renames
 func (r RenameCompleteMsg) SuccessCount() int { return r.successCount }
@@ -20,15 +23,23 @@ func (r RenameCompleteMsg) SuccessCount() int { return r.successCount }
 // ErrorCount returns the number of errors during renames
 func (r RenameCompleteMsg) ErrorCount() int { return r.errorCount }
 
+// CleanedCount returns the number of empty directories removed after renaming
+func (r RenameCompleteMsg) CleanedCount() int { return r.cleanedCount }
+
 // internal progress message for streaming rename updates
 type renameProgressMsg struct{}
 
-// prepareRenameProgress counts total operations (renames, deletions, virtual dir creations)
+// prepareRenameProgress counts total operations (encoding conversions, renames,
+// deletions, virtual dir creations) and snapshots the directories eligible for the
+// final cleanup phase.
 func (m *RenameModel) prepareRenameProgress() {
 	// Count operations without storing them to save memory
+	m.conversionCount = 0
 	m.virtualDirCount = 0
 	m.deletionCount = 0
 	m.renameCount = 0
+	m.cleanupDirs = nil
+	m.cleanedCount = 0
 
 	// Single pass to count all operation types
 	for info, _ := range m.Tree.All(context.Background()) {
@@ -41,38 +52,114 @@ func (m *RenameModel) prepareRenameProgress() {
 			m.deletionCount++
 			continue
 		}
+		if mm.ConvertFrom != "" {
+			m.conversionCount++
+		}
+		// Skip children of virtual dirs as they're handled with their parent
+		if underVirtual(n) {
+			continue
+		}
 		if mm.NeedsDirectory && mm.IsVirtual {
 			m.virtualDirCount++
 			continue
 		}
-		// Skip children of virtual dirs as they're handled with their parent
-		if parent := n.Parent(); parent != nil {
-			if pm := core.GetMeta(parent); pm != nil && pm.IsVirtual {
-				continue
-			}
-		}
-		if mm.NewName != "" && mm.NewName != n.Name() {
+		if mm.NeedsRename(n.Name()) {
 			m.renameCount++
 		}
 	}
 
-	// Total operations: virtual dirs + deletions + regular renames
-	m.totalRenameOps = m.virtualDirCount + m.deletionCount + m.renameCount
+	if m.CleanEmptyDirs {
+		m.cleanupDirs = m.collectCleanupDirs()
+	}
+
+	// Total operations: conversions + virtual dirs + deletions + regular renames + directory cleanup
+	m.totalRenameOps = m.conversionCount + m.virtualDirCount + m.deletionCount + m.renameCount + len(m.cleanupDirs)
 	m.completedOps = 0
 	m.currentOpIndex = 0
 }
 
+// collectCleanupDirs returns, bottom-up, the existing directories the cleanup phase
+// may remove. Directories that are already empty are left out unless PruneEmptyDirs
+// is set, so the run only removes what it emptied itself.
+func (m *RenameModel) collectCleanupDirs() []*treeview.Node[treeview.FileInfo] {
+	var dirs []*treeview.Node[treeview.FileInfo]
+	for info := range m.Tree.AllBottomUp(context.Background()) {
+		n := info.Node
+		if !n.Data().IsDir() {
+			continue
+		}
+		if mm := core.GetMeta(n); mm != nil && (mm.MarkedForDeletion || mm.IsVirtual) {
+			continue
+		}
+		entries, err := os.ReadDir(n.Data().Path)
+		if err != nil || (len(entries) == 0 && !m.PruneEmptyDirs) {
+			continue
+		}
+		dirs = append(dirs, n)
+	}
+	return dirs
+}
+
+// underVirtual reports whether n sits directly inside a virtual directory, in which
+// case CreateVirtualDir of that parent moves (or creates) it.
+func underVirtual(n *treeview.Node[treeview.FileInfo]) bool {
+	parent := n.Parent()
+	if parent == nil {
+		return false
+	}
+	pm := core.GetMeta(parent)
+	return pm != nil && pm.IsVirtual
+}
+
+// CurrentPath resolves where a node lives after earlier phases ran. Renames only
+// update the renamed node's own path, so descendants are rebuilt from the parent chain;
+// nodes moved out of their parent (DestDir) keep their own path.
+func CurrentPath(n *treeview.Node[treeview.FileInfo]) string {
+	parent := n.Parent()
+	if parent == nil {
+		return n.Data().Path
+	}
+	if mm := core.GetMeta(n); mm != nil && mm.DestDir != "" && mm.RenameStatus == core.RenameStatusSuccess {
+		return n.Data().Path
+	}
+	return filepath.Join(CurrentPath(parent), filepath.Base(n.Data().Path))
+}
+
+// RemoveIfEmpty removes the directory at path when it has no entries left. It
+// reports whether the directory was removed.
+func RemoveIfEmpty(path string) (bool, error) {
+	entries, err := os.ReadDir(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil || len(entries) > 0 {
+		return false, err
+	}
+	if err := os.Remove(path); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // RenameRegular renames a node; returns true only when an actual filesystem rename occurred.
 func RenameRegular(node *treeview.Node[treeview.FileInfo], mm *core.MediaMeta) (bool, error) {
 	oldPath := node.Data().Path
-	newPath := filepath.Join(filepath.Dir(oldPath), mm.NewName)
+	newPath := mm.Destination(oldPath)
 	if oldPath == newPath {
 		return false, nil
 	}
-	if _, err := os.Stat(newPath); err == nil {
-		return false, mm.Fail(fmt.Errorf("destination already exists"))
+	if mm.DestDir != "" {
+		if err := os.MkdirAll(mm.DestDir, 0755); err != nil {
+			return false, mm.Fail(err)
+		}
+	}
+	if destInfo, err := os.Stat(newPath); err == nil {
+		if !destInfo.IsDir() || !node.Data().IsDir() {
+			return false, mm.Fail(fmt.Errorf("destination already exists"))
+		}
+		return mergeRegular(node, mm, oldPath, newPath)
 	}
-	if err := os.Rename(oldPath, newPath); err != nil {
+	if err := MovePath(oldPath, newPath); err != nil {
 		return false, mm.Fail(err)
 	}
 	mm.Success()
@@ -80,17 +167,92 @@ func RenameRegular(node *treeview.Node[treeview.FileInfo], mm *core.MediaMeta) (
 	return true, nil
 }
 
-// CreateVirtualDir materializes a virtual movie directory then renames its children beneath it.
+// mergeRegular moves the contents of the directory at oldPath into the existing
+// directory at newPath. The source directory is removed once it is empty; any
+// file collisions are left in place and reported as a single error.
+func mergeRegular(node *treeview.Node[treeview.FileInfo], mm *core.MediaMeta, oldPath, newPath string) (bool, error) {
+	conflicts, err := MergeDirectory(oldPath, newPath)
+	if err != nil {
+		return false, mm.Fail(err)
+	}
+	if len(conflicts) > 0 {
+		return false, mm.Fail(fmt.Errorf("merge left %d conflicting files: %s", len(conflicts), strings.Join(conflicts, ", ")))
+	}
+	mm.Success()
+	node.Data().Path = newPath
+	return true, nil
+}
+
+// MergeDirectory moves every entry of src into dst, recursing into directories
+// that exist on both sides. Entries whose destination already exists as a file
+// are left in src and returned as conflicts (relative to src). When no conflicts
+// remain src is removed.
+func MergeDirectory(src, dst string) ([]string, error) {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return nil, err
+	}
+	var conflicts []string
+	for _, e := range entries {
+		from := filepath.Join(src, e.Name())
+		to := filepath.Join(dst, e.Name())
+		info, err := os.Stat(to)
+		switch {
+		case os.IsNotExist(err):
+			if err := MovePath(from, to); err != nil {
+				return conflicts, err
+			}
+		case err != nil:
+			return conflicts, err
+		case info.IsDir() && e.IsDir():
+			sub, err := MergeDirectory(from, to)
+			for _, c := range sub {
+				conflicts = append(conflicts, filepath.Join(e.Name(), c))
+			}
+			if err != nil {
+				return conflicts, err
+			}
+		default:
+			conflicts = append(conflicts, e.Name())
+		}
+	}
+	if len(conflicts) > 0 {
+		return conflicts, nil
+	}
+	return nil, os.Remove(src)
+}
+
+// CreateVirtualDir materializes a virtual directory (in DestDir when set, otherwise
+// the working directory) then renames its children beneath it. Virtual children, such
+// as the season folders of a virtual show, are materialized recursively.
+// When the node is flagged MergeIntoExisting an existing directory of the same name is
+// reused instead of failing; children only replace existing files through their
+// upgrade decision, disposing of the worse copy through bin.
 //
-// Returns a count of successful operations (directory creation + child renames), and contextual errors
-func CreateVirtualDir(node *treeview.Node[treeview.FileInfo], mm *core.MediaMeta) (int, []error) {
+// Returns a count of successful operations (directory creations + child renames), and contextual errors
+func CreateVirtualDir(node *treeview.Node[treeview.FileInfo], mm *core.MediaMeta, bin trash.Bin) (int, []error) {
+	base := "."
+	if mm.DestDir != "" {
+		base = mm.DestDir
+		if err := os.MkdirAll(mm.DestDir, 0755); err != nil {
+			return 0, []error{fmt.Errorf("create %s: %w", mm.DestDir, mm.Fail(err))}
+		}
+	}
+	return createVirtualDirIn(node, mm, base, bin)
+}
+
+// createVirtualDirIn creates the virtual directory node below base and moves or
+// creates its children inside it.
+func createVirtualDirIn(node *treeview.Node[treeview.FileInfo], mm *core.MediaMeta, base string, bin trash.Bin) (int, []error) {
 	successes := 0
 	errs := []error{}
 
-	dirPath := filepath.Join(".", mm.NewName)
+	dirPath := filepath.Join(base, mm.NewName)
 	if err := os.Mkdir(dirPath, 0755); err != nil {
-		errs = append(errs, fmt.Errorf("create %s: %w", mm.NewName, mm.Fail(err)))
-		return successes, errs
+		if info, statErr := os.Stat(dirPath); !mm.MergeIntoExisting || statErr != nil || !info.IsDir() {
+			errs = append(errs, fmt.Errorf("create %s: %w", mm.NewName, mm.Fail(err)))
+			return successes, errs
+		}
 	}
 
 	// Directory created successfully
@@ -104,22 +266,76 @@ func CreateVirtualDir(node *treeview.Node[treeview.FileInfo], mm *core.MediaMeta
 		if cm == nil || cm.NewName == "" {
 			continue
 		}
+		if cm.IsVirtual && cm.NeedsDirectory {
+			s, childErrs := createVirtualDirIn(child, cm, dirPath, bin)
+			successes += s
+			errs = append(errs, childErrs...)
+			continue
+		}
 		oldChildPath := child.Data().Path
 		newChildPath := filepath.Join(dirPath, cm.NewName)
-		if err := os.Rename(oldChildPath, newChildPath); err != nil {
+		if cm.Upgrade != core.UpgradeNone {
+			renamed, err := resolveUpgradeAt(child, cm, newChildPath, bin)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s -> %s: %w", child.Name(), cm.NewName, err))
+			} else if renamed {
+				successes++
+			}
+			continue
+		}
+		if _, err := os.Stat(newChildPath); err == nil {
+			errs = append(errs, fmt.Errorf("%s -> %s: %w", child.Name(), cm.NewName, cm.Fail(fmt.Errorf("destination already exists"))))
+			continue
+		}
+		if err := MovePath(oldChildPath, newChildPath); err != nil {
 			errs = append(errs, fmt.Errorf("%s -> %s: %w", child.Name(), cm.NewName, cm.Fail(err)))
 			continue
 		}
 		successes++
 		cm.Success()
 		child.Data().Path = newChildPath
+		rebaseChildren(child, oldChildPath, newChildPath)
 	}
 	return successes, errs
 }
 
+// rebaseChildren rewrites the paths below dir after it moved from oldPath to
+// newPath, so later phases find the real directories a virtual directory adopted
+// (movies grouped into a collection) at their new location.
+func rebaseChildren(dir *treeview.Node[treeview.FileInfo], oldPath, newPath string) {
+	for _, c := range dir.Children() {
+		if rel, err := filepath.Rel(oldPath, c.Data().Path); err == nil && !strings.HasPrefix(rel, "..") {
+			c.Data().Path = filepath.Join(newPath, rel)
+		}
+		rebaseChildren(c, oldPath, newPath)
+	}
+}
+
+// completeMsg reports the totals of the finished run.
+func (m *RenameModel) completeMsg() RenameCompleteMsg {
+	return RenameCompleteMsg{successCount: m.successCount, errorCount: m.errorCount, cleanedCount: m.cleanedCount}
+}
+
+// RunAll executes every pending operation synchronously, without the UI, and
+// returns the final totals. Used by instant mode and the headless commands.
+func (m *RenameModel) RunAll() RenameCompleteMsg {
+	m.prepareRenameProgress()
+	perform := m.PerformRenames()
+	for {
+		done := m.completedOps
+		if msg, ok := perform().(RenameCompleteMsg); ok {
+			return msg
+		}
+		if m.completedOps == done {
+			return m.completeMsg() // no operation could be located; avoid spinning
+		}
+	}
+}
+
 // PerformRenames walks the tree bottom‑up executing pending rename operations.
-// It skips children of virtual directories (handled by the virtual parent) and
-// aggregates success / error counts into a renameCompleteMsg.
+// It skips children of virtual directories (handled by the virtual parent),
+// finishes by removing directories the run left empty when CleanEmptyDirs is set,
+// and aggregates success / error / cleanup counts into a renameCompleteMsg.
 //
 // This function is designed to be called repeatedly by Bubble Tea, processing one
 // operation at a time and yielding control back to the UI between operations.
@@ -129,23 +345,47 @@ func (m *RenameModel) PerformRenames() tea.Cmd {
 	return func() tea.Msg {
 		// Check if all operations have been completed
 		if m.completedOps >= m.totalRenameOps {
-			return RenameCompleteMsg{successCount: m.successCount, errorCount: m.errorCount}
+			return m.completeMsg()
 		}
 		currentCount := 0
+		// Index within the phases following the encoding conversions
+		opIndex := m.currentOpIndex - m.conversionCount
 
-		// Phase 1: Virtual directories
-		// These are processed first because child files will be moved into them
-		if m.currentOpIndex < m.virtualDirCount {
+		if m.currentOpIndex < m.conversionCount {
+			// Phase 0: Subtitle encoding conversions, done in place before anything moves
+			for info := range m.Tree.All(context.Background()) {
+				node := info.Node
+				mm := core.GetMeta(node)
+				if mm != nil && mm.ConvertFrom != "" && !mm.MarkedForDeletion {
+					if currentCount == m.currentOpIndex {
+						if _, err := charset.ConvertFile(node.Data().Path); err != nil {
+							mm.ConvertStatus = core.RenameStatusError
+							mm.ConvertError = err.Error()
+							m.errorCount++
+						} else {
+							mm.ConvertStatus = core.RenameStatusSuccess
+							m.successCount++
+						}
+						m.completedOps++
+						m.currentOpIndex++
+						break // Yield control back to UI
+					}
+					currentCount++
+				}
+			}
+		} else if opIndex < m.virtualDirCount {
+			// Phase 1: Virtual directories
+			// These are processed first because child files will be moved into them
 			// Iterate through tree to find the nth virtual directory
 			for info := range m.Tree.All(context.Background()) {
 				node := info.Node
 				mm := core.GetMeta(node)
-				if mm != nil && mm.NeedsDirectory && mm.IsVirtual {
-					// Found a virtual directory
+				if mm != nil && mm.NeedsDirectory && mm.IsVirtual && !underVirtual(node) {
+					// Found a top-level virtual directory
 					// check if it's the one we need to process
-					if currentCount == m.currentOpIndex {
+					if currentCount == opIndex {
 						// Create the directory and move its children into it
-						s, errs := CreateVirtualDir(node, mm)
+						s, errs := CreateVirtualDir(node, mm, m.Bin)
 						m.successCount += s
 						m.errorCount += len(errs)
 						m.completedOps++
@@ -155,10 +395,10 @@ func (m *RenameModel) PerformRenames() tea.Cmd {
 					currentCount++
 				}
 			}
-		} else if m.currentOpIndex < m.virtualDirCount+m.deletionCount {
-			// Phase 2: Deletions (NFO files, images, etc. marked for removal)
+		} else if opIndex < m.virtualDirCount+m.deletionCount {
+			// Phase 2: Deletions (NFO files, images, etc. marked for removal), sent to m.Bin
 			// Calculate which deletion we're looking for in this phase
-			targetIndex := m.currentOpIndex - m.virtualDirCount
+			targetIndex := opIndex - m.virtualDirCount
 			for info := range m.Tree.All(context.Background()) {
 				node := info.Node
 				mm := core.GetMeta(node)
@@ -167,7 +407,7 @@ func (m *RenameModel) PerformRenames() tea.Cmd {
 					// check if it's the one we need to process
 					if currentCount == targetIndex {
 						// Attempt to delete the file
-						if err := os.Remove(node.Data().Path); err != nil {
+						if err := m.Bin.Remove(node.Data().Path); err != nil {
 							mm.Fail(err)
 							m.errorCount++
 						} else {
@@ -181,10 +421,10 @@ func (m *RenameModel) PerformRenames() tea.Cmd {
 					currentCount++
 				}
 			}
-		} else {
+		} else if opIndex < m.virtualDirCount+m.deletionCount+m.renameCount {
 			// Phase 3: Regular renames (standard file/folder renames)
 			// Process bottom-up so child renames happen before parent renames
-			targetIndex := m.currentOpIndex - m.virtualDirCount - m.deletionCount
+			targetIndex := opIndex - m.virtualDirCount - m.deletionCount
 			for info := range m.Tree.AllBottomUp(context.Background()) {
 				node := info.Node
 				mm := core.GetMeta(node)
@@ -196,18 +436,23 @@ func (m *RenameModel) PerformRenames() tea.Cmd {
 					continue
 				}
 				// Skip children of virtual dirs (they're moved by their parent's CreateVirtualDir)
-				if parent := node.Parent(); parent != nil {
-					if pm := core.GetMeta(parent); pm != nil && pm.IsVirtual {
-						continue
-					}
+				if underVirtual(node) {
+					continue
 				}
 				// Only process nodes that actually need renaming
-				if mm.NewName != "" && mm.NewName != node.Name() {
+				if mm.NeedsRename(node.Name()) {
 					// Found a file to rename
 					// check if it's the one we need to process
 					if currentCount == targetIndex {
 						// Perform the filesystem rename operation
-						if renamed, err := RenameRegular(node, mm); err != nil {
+						var renamed bool
+						var err error
+						if mm.Upgrade != core.UpgradeNone {
+							renamed, err = ResolveUpgrade(node, mm, m.Bin)
+						} else {
+							renamed, err = RenameRegular(node, mm)
+						}
+						if err != nil {
 							m.errorCount++
 						} else if renamed {
 							m.successCount++
@@ -219,11 +464,25 @@ func (m *RenameModel) PerformRenames() tea.Cmd {
 					currentCount++
 				}
 			}
+		} else {
+			// Phase 4: Remove directories the earlier phases left empty (snapshot is bottom-up)
+			node := m.cleanupDirs[opIndex-m.virtualDirCount-m.deletionCount-m.renameCount]
+			removed, err := RemoveIfEmpty(CurrentPath(node))
+			if err != nil {
+				if mm := core.GetMeta(node); mm != nil {
+					mm.Fail(err)
+				}
+				m.errorCount++
+			} else if removed {
+				m.cleanedCount++
+			}
+			m.completedOps++
+			m.currentOpIndex++
 		}
 
 		// Check again if all operations are now complete
 		if m.completedOps >= m.totalRenameOps {
-			return RenameCompleteMsg{successCount: m.successCount, errorCount: m.errorCount}
+			return m.completeMsg()
 		}
 
 		// Return progress message to continue processing in next Bubble Tea cycle
@@ -2,8 +2,10 @@ package tui
 
 import (
 	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/trash"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/Digital-Shane/treeview"
@@ -117,7 +119,7 @@ func TestCreateVirtualDir_MkdirFails(t *testing.T) {
 	mm.NewName = "Already"
 	mm.IsVirtual = true
 	mm.NeedsDirectory = true
-	successes, errs := CreateVirtualDir(n, mm)
+	successes, errs := CreateVirtualDir(n, mm, trash.Bin{Hard: true})
 	if successes != 0 || len(errs) != 1 {
 		t.Errorf("createVirtualDir(mkdirFail) = (%d,%d errs), want (0,1)", successes, len(errs))
 	}
@@ -161,7 +163,7 @@ func TestCreateVirtualDir_SuccessChildrenMixed(t *testing.T) {
 	vdir.AddChild(c1)
 	vdir.AddChild(c2)
 	vdir.AddChild(c3)
-	successes, errs := CreateVirtualDir(vdir, mm)
+	successes, errs := CreateVirtualDir(vdir, mm, trash.Bin{Hard: true})
 	if successes != 2 || len(errs) != 1 {
 		t.Errorf("createVirtualDir(mixed) counts = (%d successes,%d errs), want (2,1)", successes, len(errs))
 	}
@@ -195,6 +197,10 @@ func TestRenameCompleteMsg(t *testing.T) {
 	if got := msg.ErrorCount(); got != 2 {
 		t.Errorf("RenameCompleteMsg.ErrorCount() = %d, want 2", got)
 	}
+
+	if got := (RenameCompleteMsg{cleanedCount: 3}).CleanedCount(); got != 3 {
+		t.Errorf("RenameCompleteMsg.CleanedCount() = %d, want 3", got)
+	}
 }
 
 func TestPerformRenames_DeletionPhase(t *testing.T) {
@@ -203,6 +209,7 @@ func TestPerformRenames_DeletionPhase(t *testing.T) {
 	cwd, _ := os.Getwd()
 	defer os.Chdir(cwd)
 	os.Chdir(tmp)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data"))
 
 	// Create files to delete
 	os.WriteFile("delete1.nfo", []byte("nfo1"), 0644)
@@ -244,6 +251,11 @@ func TestPerformRenames_DeletionPhase(t *testing.T) {
 		t.Errorf("PerformRenames deletion phase: success=%d, errors=%d, want success=2, errors=0", rc.successCount, rc.errorCount)
 	}
 
+	// Deleted files should be recoverable from the trash
+	if _, err := os.Stat(filepath.Join("data", "Trash", "files", "delete1.nfo")); err != nil {
+		t.Errorf("delete1.nfo should have been moved to the trash: %v", err)
+	}
+
 	// Files should be deleted
 	if _, err := os.Stat("delete1.nfo"); err == nil {
 		t.Errorf("delete1.nfo should have been deleted")
@@ -262,6 +274,7 @@ func TestPerformRenames_DeletionError(t *testing.T) {
 	cwd, _ := os.Getwd()
 	defer os.Chdir(cwd)
 	os.Chdir(tmp)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data"))
 
 	// Create node for non-existent file
 	deleteFile := fsTestNode("nonexistent.nfo", false, "nonexistent.nfo")
@@ -294,3 +307,238 @@ func TestPerformRenames_DeletionError(t *testing.T) {
 		t.Errorf("Deletion failure should set RenameStatusError, got %v", deleteFileMeta.RenameStatus)
 	}
 }
+
+func TestRenameRegular_MergesIntoExistingDirectory(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	for _, dir := range []string{"Show.2024/Season 01", "Show.2024/Season 02", "Show (2024)/Season 01"} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.WriteFile("Show.2024/Season 01/S01E02.mkv", []byte("b"), 0644)
+	os.WriteFile("Show.2024/Season 02/S02E01.mkv", []byte("c"), 0644)
+	os.WriteFile("Show (2024)/Season 01/S01E01.mkv", []byte("a"), 0644)
+
+	n := fsTestNode("Show.2024", true, "Show.2024")
+	mm := core.EnsureMeta(n)
+	mm.NewName = "Show (2024)"
+	renamed, err := RenameRegular(n, mm)
+	if err != nil || !renamed {
+		t.Fatalf("RenameRegular(merge) = (%v,%v), want (true,<nil>)", renamed, err)
+	}
+	for _, p := range []string{"Show (2024)/Season 01/S01E01.mkv", "Show (2024)/Season 01/S01E02.mkv", "Show (2024)/Season 02/S02E01.mkv"} {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("RenameRegular(merge) missing %s: %v", p, err)
+		}
+	}
+	if _, err := os.Stat("Show.2024"); !os.IsNotExist(err) {
+		t.Errorf("RenameRegular(merge) source directory still exists")
+	}
+	if mm.RenameStatus != core.RenameStatusSuccess || n.Data().Path != "Show (2024)" {
+		t.Errorf("RenameRegular(merge) meta = %+v path = %s, want success at Show (2024)", mm, n.Data().Path)
+	}
+}
+
+func TestRenameRegular_MergeReportsFileConflicts(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	os.MkdirAll("src/Season 01", 0755)
+	os.MkdirAll("dest/Season 01", 0755)
+	os.WriteFile("src/Season 01/S01E01.mkv", []byte("new"), 0644)
+	os.WriteFile("src/Season 01/S01E02.mkv", []byte("new"), 0644)
+	os.WriteFile("dest/Season 01/S01E01.mkv", []byte("old"), 0644)
+
+	n := fsTestNode("src", true, "src")
+	mm := core.EnsureMeta(n)
+	mm.NewName = "dest"
+	renamed, err := RenameRegular(n, mm)
+	if err == nil || renamed {
+		t.Fatalf("RenameRegular(merge conflict) = (%v,%v), want (false,error)", renamed, err)
+	}
+	if !strings.Contains(mm.RenameError, filepath.Join("Season 01", "S01E01.mkv")) {
+		t.Errorf("RenameRegular(merge conflict) error = %q, want conflicting path", mm.RenameError)
+	}
+	if data, _ := os.ReadFile("dest/Season 01/S01E01.mkv"); string(data) != "old" {
+		t.Errorf("RenameRegular(merge conflict) overwrote existing file")
+	}
+	if _, err := os.Stat("dest/Season 01/S01E02.mkv"); err != nil {
+		t.Errorf("RenameRegular(merge conflict) non-conflicting file not moved: %v", err)
+	}
+	if _, err := os.Stat("src/Season 01/S01E01.mkv"); err != nil {
+		t.Errorf("RenameRegular(merge conflict) conflicting file should stay in source: %v", err)
+	}
+}
+
+func TestCreateVirtualDir_MergeIntoExisting(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	os.Mkdir("Movie (2020)", 0755)
+	os.WriteFile("Movie (2020)/Movie (2020).mkv", []byte("old"), 0644)
+	os.WriteFile("movie.en.srt", []byte("sub"), 0644)
+	os.WriteFile("movie.mkv", []byte("new"), 0644)
+
+	vdir := fsTestNode("movie", true, "movie")
+	mm := core.EnsureMeta(vdir)
+	mm.NewName = "Movie (2020)"
+	mm.IsVirtual = true
+	mm.NeedsDirectory = true
+	mm.MergeIntoExisting = true
+	sub := fsTestNode("movie.en.srt", false, "movie.en.srt")
+	core.EnsureMeta(sub).NewName = "Movie (2020).en.srt"
+	vid := fsTestNode("movie.mkv", false, "movie.mkv")
+	core.EnsureMeta(vid).NewName = "Movie (2020).mkv"
+	vdir.AddChild(sub)
+	vdir.AddChild(vid)
+
+	successes, errs := CreateVirtualDir(vdir, mm, trash.Bin{Hard: true})
+	if successes != 2 || len(errs) != 1 {
+		t.Errorf("CreateVirtualDir(merge) = (%d,%d errs), want (2,1)", successes, len(errs))
+	}
+	if _, err := os.Stat("Movie (2020)/Movie (2020).en.srt"); err != nil {
+		t.Errorf("CreateVirtualDir(merge) subtitle not moved: %v", err)
+	}
+	if data, _ := os.ReadFile("Movie (2020)/Movie (2020).mkv"); string(data) != "old" {
+		t.Errorf("CreateVirtualDir(merge) overwrote existing video")
+	}
+}
+
+func TestPerformRenames_CleanupPhase(t *testing.T) {
+	for _, prune := range []bool{false, true} {
+		tmp := t.TempDir()
+		cwd, _ := os.Getwd()
+		os.Chdir(tmp)
+		t.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data"))
+		os.MkdirAll(filepath.Join("Show.S01", "Season.1"), 0755)
+		os.WriteFile(filepath.Join("Show.S01", "Season.1", "ep.nfo"), []byte("nfo"), 0644)
+		os.Mkdir("Empty", 0755)
+		os.Mkdir("Kept", 0755)
+		os.WriteFile(filepath.Join("Kept", "ep.mkv"), []byte("video"), 0644)
+
+		show := fsTestNode("Show.S01", true, "Show.S01")
+		core.EnsureMeta(show).NewName = "Show"
+		season := fsTestNode("Season.1", true, filepath.Join("Show.S01", "Season.1"))
+		core.EnsureMeta(season).NewName = "Season 01"
+		nfo := fsTestNode("ep.nfo", false, filepath.Join("Show.S01", "Season.1", "ep.nfo"))
+		core.EnsureMeta(nfo).MarkedForDeletion = true
+		season.AddChild(nfo)
+		show.AddChild(season)
+		kept := fsTestNode("Kept", true, "Kept")
+		kept.AddChild(fsTestNode("ep.mkv", false, filepath.Join("Kept", "ep.mkv")))
+		empty := fsTestNode("Empty", true, "Empty")
+
+		model := NewRenameModel(treeview.NewTree([]*treeview.Node[treeview.FileInfo]{show, kept, empty}))
+		model.CleanEmptyDirs = true
+		model.PruneEmptyDirs = prune
+		model.prepareRenameProgress()
+
+		var rc RenameCompleteMsg
+		for {
+			if msg, ok := model.PerformRenames()().(RenameCompleteMsg); ok {
+				rc = msg
+				break
+			}
+		}
+
+		wantCleaned := 2
+		if prune {
+			wantCleaned = 3
+		}
+		if rc.CleanedCount() != wantCleaned || rc.ErrorCount() != 0 {
+			t.Errorf("PerformRenames(prune=%v) cleaned=%d errors=%d, want cleaned=%d errors=0", prune, rc.CleanedCount(), rc.ErrorCount(), wantCleaned)
+		}
+		if _, err := os.Stat("Show"); !os.IsNotExist(err) {
+			t.Errorf("PerformRenames(prune=%v) left emptied directory Show: %v", prune, err)
+		}
+		if _, err := os.Stat("Kept"); err != nil {
+			t.Errorf("PerformRenames(prune=%v) removed non-empty directory Kept: %v", prune, err)
+		}
+		if _, err := os.Stat("Empty"); os.IsNotExist(err) != prune {
+			t.Errorf("PerformRenames(prune=%v) Empty exists = %v", prune, err == nil)
+		}
+		os.Chdir(cwd)
+	}
+}
+
+func TestPerformRenames_NestedVirtualDirs(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	os.WriteFile("Show.A.S01E01.mkv", []byte("a"), 0644)
+	os.WriteFile("Show.A.S02E05.mkv", []byte("b"), 0644)
+
+	virtual := func(name, path string) *treeview.Node[treeview.FileInfo] {
+		n := fsTestNode(name, true, path)
+		mm := core.EnsureMeta(n)
+		mm.NewName = name
+		mm.IsVirtual = true
+		mm.NeedsDirectory = true
+		return n
+	}
+	show := virtual("Show A", "Show A")
+	for _, ep := range []struct{ file, season, name string }{
+		{"Show.A.S01E01.mkv", "Season 01", "S01E01.mkv"},
+		{"Show.A.S02E05.mkv", "Season 02", "S02E05.mkv"},
+	} {
+		season := virtual(ep.season, filepath.Join("Show A", ep.season))
+		f := fsTestNode(ep.file, false, ep.file)
+		core.EnsureMeta(f).NewName = ep.name
+		season.AddChild(f)
+		show.AddChild(season)
+	}
+
+	model := NewRenameModel(treeview.NewTree([]*treeview.Node[treeview.FileInfo]{show}))
+	rc := model.RunAll()
+	if model.virtualDirCount != 1 {
+		t.Errorf("prepareRenameProgress() virtualDirCount = %d, want 1 (seasons belong to the show)", model.virtualDirCount)
+	}
+	if rc.SuccessCount() != 5 || rc.ErrorCount() != 0 {
+		t.Errorf("RunAll(nested) = (%d successes, %d errors), want (5, 0)", rc.SuccessCount(), rc.ErrorCount())
+	}
+	for _, want := range []string{"Show A/Season 01/S01E01.mkv", "Show A/Season 02/S02E05.mkv"} {
+		if _, err := os.Stat(want); err != nil {
+			t.Errorf("RunAll(nested) did not create %s: %v", want, err)
+		}
+	}
+}
+
+func TestCreateVirtualDir_Upgrade(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	os.Mkdir("Movie (2020)", 0755)
+	os.WriteFile("Movie (2020)/Movie (2020).mkv", []byte("old"), 0644)
+	os.WriteFile("movie.mkv", []byte("new"), 0644)
+
+	vdir := fsTestNode("movie", true, "movie")
+	mm := core.EnsureMeta(vdir)
+	mm.NewName = "Movie (2020)"
+	mm.IsVirtual = true
+	mm.NeedsDirectory = true
+	mm.MergeIntoExisting = true
+	vid := fsTestNode("movie.mkv", false, "movie.mkv")
+	vm := core.EnsureMeta(vid)
+	vm.NewName = "Movie (2020).mkv"
+	vm.Upgrade = core.UpgradeReplaceExisting
+	vm.KeepSuffix = ".old"
+	vdir.AddChild(vid)
+
+	successes, errs := CreateVirtualDir(vdir, mm, trash.Bin{Hard: true})
+	if successes != 2 || len(errs) != 0 {
+		t.Errorf("CreateVirtualDir(upgrade) = (%d,%v), want (2,[])", successes, errs)
+	}
+	if data, _ := os.ReadFile("Movie (2020)/Movie (2020).mkv"); string(data) != "new" {
+		t.Errorf("CreateVirtualDir(upgrade) video = %q, want new", data)
+	}
+	if data, _ := os.ReadFile("Movie (2020)/Movie (2020).old.mkv"); string(data) != "old" {
+		t.Errorf("CreateVirtualDir(upgrade) kept copy = %q, want old", data)
+	}
+}
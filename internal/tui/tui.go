@@ -8,7 +8,9 @@ import (
 	"strings"
 
 	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/ignore"
 	"github.com/Digital-Shane/title-tidy/internal/media"
+	"github.com/Digital-Shane/title-tidy/internal/trash"
 
 	"github.com/Digital-Shane/treeview"
 	"github.com/charmbracelet/bubbles/progress"
@@ -27,10 +29,13 @@ var (
 		"seasons":    "📁",
 		"episodes":   "🎬",
 		"video":      "🎥",
+		"extra":      "🎞",
 		"subtitles":  "📄",
 		"needrename": "✓",
 		"nochange":   "=",
 		"delete":     "🗑",
+		"merge":      "🔀",
+		"upgrade":    "⏫",
 		"success":    "✅",
 		"error":      "❌",
 		"arrows":     "↑↓←→",
@@ -44,10 +49,13 @@ var (
 		"seasons":    "[D]",
 		"episodes":   "[E]",
 		"video":      "[V]",
+		"extra":      "[X]",
 		"subtitles":  "[S]",
 		"needrename": "[+]",
 		"nochange":   "[=]",
 		"delete":     "[x]",
+		"merge":      "[>]",
+		"upgrade":    "[^]",
 		"success":    "[v]",
 		"error":      "[!]",
 		"arrows":     "^v<>",
@@ -82,14 +90,26 @@ type RenameModel struct {
 	progressModel    progress.Model
 	progressVisible  bool
 	currentOpIndex   int
+	conversionCount  int
 	virtualDirCount  int
 	deletionCount    int
 	renameCount      int
+	cleanupDirs      []*treeview.Node[treeview.FileInfo]
+	cleanedCount     int
 	width            int
 	height           int
 	IsMovieMode      bool
 	DeleteNFO        bool
 	DeleteImages     bool
+	Bin              trash.Bin
+	CleanEmptyDirs   bool // remove directories emptied by the run
+	PruneEmptyDirs   bool // also remove directories that were empty before the run
+	// Reclassify, when set, switches the focused top-level entry to the next
+	// rename mode (auto mode) and returns the nodes that replace it.
+	Reclassify func(*treeview.Node[treeview.FileInfo]) ([]*treeview.Node[treeview.FileInfo], error)
+	// Ignore, when set, lets "D" remove the focused node and record it in the
+	// ignore file so later runs skip it too.
+	Ignore *ignore.Matcher
 
 	// Layout metrics
 	treeWidth   int
@@ -102,7 +122,7 @@ type RenameModel struct {
 	statsDirty bool
 
 	// Icon support
-	iconSet     map[string]string
+	iconSet map[string]string
 }
 
 // NewRenameModel returns an initialized RenameModel for the provided tree with
@@ -221,6 +241,32 @@ func (m *RenameModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.statsDirty = true
 			}
 			return m, nil
+		case "D":
+			if m.Ignore != nil && !m.renameInProgress {
+				if focusedNode := m.TuiTreeModel.Tree.GetFocusedNode(); focusedNode != nil {
+					if err := m.ignoreNode(focusedNode); err != nil {
+						core.EnsureMeta(focusedNode).Fail(err)
+						return m, nil
+					}
+					m.TuiTreeModel.Tree.Move(context.Background(), -1)
+					m.removeNodeFromTree(focusedNode)
+					m.statsDirty = true
+				}
+				return m, nil
+			}
+		case "m":
+			if m.Reclassify != nil && !m.renameInProgress {
+				if n := m.TuiTreeModel.Tree.GetFocusedNode(); n != nil {
+					for n.Parent() != nil {
+						n = n.Parent()
+					}
+					if err := m.reclassify(n); err != nil {
+						core.EnsureMeta(n).Fail(err)
+					}
+					m.statsDirty = true
+				}
+				return m, nil
+			}
 		case "r":
 			if !m.renameInProgress {
 				m.renameInProgress = true
@@ -258,6 +304,7 @@ func (m *RenameModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.renameComplete = true
 		m.successCount = msg.successCount
 		m.errorCount = msg.errorCount
+		m.cleanedCount = msg.cleanedCount
 		m.statsDirty = true
 		m.progressVisible = false
 		return m, nil
@@ -332,9 +379,17 @@ func (m *RenameModel) renderStatusBar() string {
 		combined := fmt.Sprintf("%s  %s", bar, statusText)
 		return statusStyleBase.Width(m.width).Render(combined)
 	}
-	statusText := fmt.Sprintf("%s: Navigate  PgUp/PgDn: Page  %s: Expand/Collapse  │  r: Rename  │  d: Remove  │  esc: Quit", 
+	mode := ""
+	if m.Reclassify != nil {
+		mode = "m: Mode  │  "
+	}
+	if m.Ignore != nil {
+		mode += "D: Ignore  │  "
+	}
+	statusText := fmt.Sprintf("%s: Navigate  PgUp/PgDn: Page  %s: Expand/Collapse  │  r: Rename  │  d: Remove  │  %sesc: Quit",
 		m.getIcon("arrows")[:2], // First two characters (up/down arrows)
-		m.getIcon("arrows")[2:]) // Last two characters (left/right arrows)
+		m.getIcon("arrows")[2:], // Last two characters (left/right arrows)
+		mode)
 	return statusStyleBase.Width(m.width).Render(statusText)
 }
 
@@ -391,13 +446,28 @@ func (m *RenameModel) renderStatsPanel() string {
 	b.WriteString("Files Found:\n")
 	if m.IsMovieMode {
 		fmt.Fprintf(&b, "  %s %-12s %d\n", m.getIcon("movie"), "Movies:", stats.movieCount)
-		fmt.Fprintf(&b, "  %s %-12s %d\n", m.getIcon("video"), "Video Files:", stats.movieFileCount-stats.subtitleCount)
+		if stats.collectionCount > 0 {
+			fmt.Fprintf(&b, "  %s %-12s %d\n", m.getIcon("movie"), "Collections:", stats.collectionCount)
+		}
+		fmt.Fprintf(&b, "  %s %-12s %d\n", m.getIcon("video"), "Video Files:", stats.videoCount)
 		fmt.Fprintf(&b, "  %s %-12s %d\n", m.getIcon("subtitles"), "Subtitles:", stats.subtitleCount)
 	} else {
 		fmt.Fprintf(&b, "  %s %-12s %d\n", m.getIcon("tv"), "TV Shows:", stats.showCount)
 		fmt.Fprintf(&b, "  %s %-12s %d\n", m.getIcon("seasons"), "Seasons:", stats.seasonCount)
 		fmt.Fprintf(&b, "  %s %-12s %d\n", m.getIcon("episodes"), "Episodes:", stats.episodeCount)
 		fmt.Fprintf(&b, "  %s %-12s %d\n", m.getIcon("subtitles"), "Subtitles:", stats.subtitleCount)
+		if stats.movieCount > 0 { // mixed library in auto mode
+			fmt.Fprintf(&b, "  %s %-12s %d\n", m.getIcon("movie"), "Movies:", stats.movieCount)
+		}
+	}
+	if stats.extraCount > 0 {
+		fmt.Fprintf(&b, "  %s %-12s %d\n", m.getIcon("extra"), "Extras:", stats.extraCount)
+	}
+	if stats.audioCount > 0 {
+		fmt.Fprintf(&b, "  %s %-12s %d\n", m.getIcon("video"), "Audio:", stats.audioCount)
+	}
+	if stats.sidecarCount > 0 {
+		fmt.Fprintf(&b, "  %s %-12s %d\n", m.getIcon("video"), "Sidecars:", stats.sidecarCount)
 	}
 
 	b.WriteString("\nRename Status:\n")
@@ -406,12 +476,30 @@ func (m *RenameModel) renderStatsPanel() string {
 	if stats.toDeleteCount > 0 {
 		fmt.Fprintf(&b, "  %s %-13s %d\n", m.getIcon("delete"), "To delete:", stats.toDeleteCount)
 	}
+	if stats.mergeCount > 0 {
+		fmt.Fprintf(&b, "  %s %-13s %d\n", m.getIcon("merge"), "To merge:", stats.mergeCount)
+	}
+	if stats.upgradeCount > 0 {
+		fmt.Fprintf(&b, "  %s %-13s %d\n", m.getIcon("upgrade"), "Upgrades:", stats.upgradeCount)
+	}
+	if stats.duplicateCount > 0 {
+		fmt.Fprintf(&b, "  %s %-13s %d\n", m.getIcon("nochange"), "Duplicates:", stats.duplicateCount)
+	}
+	if stats.sampleCount > 0 {
+		fmt.Fprintf(&b, "  %s %-13s %d\n", m.getIcon("nochange"), "Samples:", stats.sampleCount)
+	}
+	if stats.convertCount > 0 {
+		fmt.Fprintf(&b, "  %s %-13s %d\n", m.getIcon("subtitles"), "To convert:", stats.convertCount)
+	}
 
-	if stats.successCount > 0 || stats.errorCount > 0 {
+	if stats.successCount > 0 || stats.errorCount > 0 || stats.cleanedCount > 0 {
 		b.WriteString("\nLast Operation:\n")
 		if stats.successCount > 0 {
 			fmt.Fprintf(&b, "  %s %-12s %d\n", m.getIcon("success"), "Success:", stats.successCount)
 		}
+		if stats.cleanedCount > 0 {
+			fmt.Fprintf(&b, "  %s %-12s %d\n", m.getIcon("delete"), "Cleaned:", stats.cleanedCount)
+		}
 		if stats.errorCount > 0 {
 			fmt.Fprintf(&b, "  %s %-12s %d\n", m.getIcon("error"), "Errors:", stats.errorCount)
 		}
@@ -430,7 +518,7 @@ func (m *RenameModel) renderStatsPanel() string {
 	if m.IsMovieMode {
 		totalItems = stats.movieCount + stats.movieFileCount
 	} else {
-		totalItems = stats.showCount + stats.seasonCount + stats.episodeCount + stats.subtitleCount
+		totalItems = stats.showCount + stats.seasonCount + stats.episodeCount + stats.subtitleCount + stats.movieCount + stats.movieFileCount
 	}
 
 	fmt.Fprintf(&b, "\nTotal items: %d\n", totalItems)
@@ -448,23 +536,43 @@ func (m *RenameModel) renderStatsPanel() string {
 // Fields:
 //   - showCount / seasonCount / episodeCount: counts of TV hierarchy nodes.
 //   - movieCount / movieFileCount: counts for movie mode (directories & files).
-//   - subtitleCount: number of subtitle files (subset of episode/movie files).
+//   - collectionCount: collection folders grouping movies.
+//   - extraCount: trailers, featurettes and other extras videos.
+//   - videoCount / subtitleCount / audioCount / sidecarCount: files per extension
+//     registry category (subsets of episode/movie files).
 //   - needRenameCount: nodes where NewName differs from current name.
 //   - noChangeCount: nodes with a proposed name identical to current name.
 //   - successCount / errorCount: results from the last performRenames run.
+//   - cleanedCount: empty directories removed at the end of the last run.
 //   - toDeleteCount: nodes marked for deletion.
+//   - mergeCount: directories that will be merged into an existing destination.
+//   - upgradeCount / duplicateCount: files replacing a worse existing copy, and
+//     files that are themselves the worse copy of an existing file.
+//   - sampleCount: release samples excluded from renaming.
+//   - convertCount: subtitles planned for conversion to UTF-8.
 type Statistics struct {
 	showCount       int
 	seasonCount     int
 	episodeCount    int
+	videoCount      int
 	subtitleCount   int
+	audioCount      int
+	sidecarCount    int
 	movieCount      int
 	movieFileCount  int
+	collectionCount int
+	extraCount      int
 	needRenameCount int
 	noChangeCount   int
 	successCount    int
 	errorCount      int
+	cleanedCount    int
 	toDeleteCount   int
+	mergeCount      int
+	upgradeCount    int
+	duplicateCount  int
+	sampleCount     int
+	convertCount    int
 }
 
 // calculateStats walks the tree to produce aggregate counts while preserving
@@ -475,6 +583,7 @@ func (m *RenameModel) calculateStats() Statistics {
 		// always ensure latest success/error counts reflected even if cache reused
 		m.statsCache.successCount = m.successCount
 		m.statsCache.errorCount = m.errorCount
+		m.statsCache.cleanedCount = m.cleanedCount
 		return m.statsCache
 	}
 
@@ -496,14 +605,43 @@ func (m *RenameModel) calculateStats() Statistics {
 			stats.movieCount++
 		case core.MediaMovieFile:
 			stats.movieFileCount++
+		case core.MediaCollection:
+			stats.collectionCount++
+		case core.MediaExtra:
+			if !node.Data().IsDir() {
+				stats.extraCount++
+			}
+		case core.MediaSample:
+			stats.sampleCount++
 		}
-		if !node.Data().IsDir() && media.IsSubtitle(node.Data().Name()) {
-			stats.subtitleCount++
+		if !node.Data().IsDir() {
+			switch media.CategoryOf(node.Data().Name()) {
+			case media.CategoryVideo:
+				stats.videoCount++
+			case media.CategorySubtitle:
+				stats.subtitleCount++
+			case media.CategoryAudio:
+				stats.audioCount++
+			case media.CategorySidecar:
+				stats.sidecarCount++
+			}
+		}
+		if mm.MergeIntoExisting {
+			stats.mergeCount++
+		}
+		if mm.ConvertFrom != "" && !mm.MarkedForDeletion {
+			stats.convertCount++
+		}
+		switch mm.Upgrade {
+		case core.UpgradeReplaceExisting:
+			stats.upgradeCount++
+		case core.UpgradeDiscardIncoming, core.UpgradeKeepIncoming:
+			stats.duplicateCount++
 		}
 		if mm.MarkedForDeletion {
 			stats.toDeleteCount++
 		} else if mm.NewName != "" {
-			if mm.NewName != node.Name() {
+			if mm.NeedsRename(node.Name()) {
 				stats.needRenameCount++
 			} else {
 				stats.noChangeCount++
@@ -512,6 +650,7 @@ func (m *RenameModel) calculateStats() Statistics {
 	}
 	stats.successCount = m.successCount
 	stats.errorCount = m.errorCount
+	stats.cleanedCount = m.cleanedCount
 	m.statsCache = stats
 	m.statsDirty = false
 	return stats
@@ -541,6 +680,42 @@ func (m *RenameModel) removeNodeFromTree(nodeToRemove *treeview.Node[treeview.Fi
 	parent.SetChildren(filteredChildren)
 }
 
+// ignoreNode appends n to the ignore file. Virtual directories do not exist on disk,
+// so the real entries they wrap are recorded instead.
+func (m *RenameModel) ignoreNode(n *treeview.Node[treeview.FileInfo]) error {
+	if mm := core.GetMeta(n); mm != nil && mm.IsVirtual {
+		for _, c := range n.Children() {
+			if err := m.ignoreNode(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return m.Ignore.Add(n.Data().Path, n.Data().IsDir())
+}
+
+// reclassify swaps the top-level entry n for the nodes Reclassify rebuilds for it
+// and keeps the focus on the first of them.
+func (m *RenameModel) reclassify(n *treeview.Node[treeview.FileInfo]) error {
+	nodes, err := m.Reclassify(n)
+	if err != nil {
+		return err
+	}
+	var roots []*treeview.Node[treeview.FileInfo]
+	for _, r := range m.TuiTreeModel.Tree.Nodes() {
+		if r == n {
+			roots = append(roots, nodes...)
+			continue
+		}
+		roots = append(roots, r)
+	}
+	m.TuiTreeModel.Tree.SetNodes(roots)
+	if len(nodes) > 0 {
+		m.TuiTreeModel.Tree.SetFocusedID(context.Background(), nodes[0].ID())
+	}
+	return nil
+}
+
 // removeRootNode removes a root node from the tree's internal nodes slice
 func (m *RenameModel) removeRootNode(nodeToRemove *treeview.Node[treeview.FileInfo]) {
 	// Get the current root nodes and filter out the node to remove
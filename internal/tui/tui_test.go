@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -8,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/ignore"
 	"github.com/google/go-cmp/cmp"
 
 	"github.com/Digital-Shane/treeview"
@@ -140,6 +142,47 @@ func TestCalculateStatsMovieMode(t *testing.T) {
 	}
 }
 
+func TestCalculateStatsCollections(t *testing.T) {
+	t.Parallel()
+	tree := buildMovieTestTree()
+	movie := tree.Nodes()[0]
+	collection := tuiTestNode("Movie Collection", true)
+	cm := core.EnsureMeta(collection)
+	cm.Type = core.MediaCollection
+	cm.NewName = "Movie Collection"
+	cm.IsVirtual = true
+	cm.NeedsDirectory = true
+	collection.AddChild(movie)
+	tree.SetNodes([]*treeview.Node[treeview.FileInfo]{collection})
+
+	m := NewRenameModel(tree)
+	m.IsMovieMode = true
+	if stats := m.calculateStats(); stats.collectionCount != 1 || stats.movieCount != 1 {
+		t.Errorf("calculateStats(collection) counts = (%d %d) want (1 1)", stats.collectionCount, stats.movieCount)
+	}
+	if panel := m.renderStatsPanel(); !strings.Contains(panel, "Collections:") {
+		t.Errorf("renderStatsPanel(movie) missing collection count")
+	}
+}
+
+func TestCalculateStatsExtras(t *testing.T) {
+	t.Parallel()
+	tree := buildMovieTestTree()
+	trailer := tuiTestNode("Movie-trailer.mkv", false)
+	core.EnsureMeta(trailer).Type = core.MediaExtra
+	core.GetMeta(trailer).NewName = trailer.Name()
+	tree.Nodes()[0].AddChild(trailer)
+
+	m := NewRenameModel(tree)
+	m.IsMovieMode = true
+	if stats := m.calculateStats(); stats.extraCount != 1 || stats.movieFileCount != 2 {
+		t.Errorf("calculateStats(extras) counts = (%d %d) want (1 2)", stats.extraCount, stats.movieFileCount)
+	}
+	if panel := m.renderStatsPanel(); !strings.Contains(panel, "Extras:") {
+		t.Errorf("renderStatsPanel(movie) missing extras count")
+	}
+}
+
 func TestKeyRenameFlow(t *testing.T) {
 	t.Parallel()
 	n := tuiTestNode("file.txt", false)
@@ -473,6 +516,60 @@ func TestKeyHandling(t *testing.T) {
 	}
 }
 
+func TestKeyReclassify(t *testing.T) {
+	t.Parallel()
+	tree := buildTVTestTree()
+	m := NewRenameModel(tree)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}}) // no hook outside auto mode
+
+	var got *treeview.Node[treeview.FileInfo]
+	rebuilt := tuiTestNode("Rebuilt", true)
+	m.Reclassify = func(n *treeview.Node[treeview.FileInfo]) ([]*treeview.Node[treeview.FileInfo], error) {
+		got = n
+		return []*treeview.Node[treeview.FileInfo]{rebuilt}, nil
+	}
+	want := tree.Nodes()[0]
+	m.TuiTreeModel.Tree.Move(context.Background(), 2) // focus a child of the show
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+	if got != want {
+		t.Errorf("Update('m') reclassified %v, want top-level %v", got, want.Name())
+	}
+	if roots := tree.Nodes(); roots[0] != rebuilt {
+		t.Errorf("Update('m') tree root = %v, want the rebuilt entry", roots[0].Name())
+	}
+	if focused := m.TuiTreeModel.Tree.GetFocusedNode(); focused != rebuilt {
+		t.Errorf("Update('m') focused %v, want the rebuilt entry", focused)
+	}
+	if !strings.Contains(m.renderStatusBar(), "m: Mode") {
+		t.Errorf("renderStatusBar() missing mode hint when Reclassify is set")
+	}
+}
+
+func TestKeyIgnore(t *testing.T) {
+	root := t.TempDir()
+	movie := tuiTestNode("Movie", true)
+	movie.Data().Path = filepath.Join(root, "Movie")
+	clip := tuiTestNode("clip.mkv", false)
+	clip.Data().Path = filepath.Join(root, "clip.mkv")
+	m := NewRenameModel(treeview.NewTree([]*treeview.Node[treeview.FileInfo]{movie, clip}))
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'D'}}) // no matcher: nothing happens
+	if len(m.TuiTreeModel.Tree.Nodes()) != 2 {
+		t.Fatalf("Update('D') without Ignore removed a node")
+	}
+
+	m.Ignore = ignore.New(root)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'D'}})
+	if nodes := m.TuiTreeModel.Tree.Nodes(); len(nodes) != 1 || nodes[0] != clip {
+		t.Errorf("Update('D') left %d nodes, want only clip.mkv", len(nodes))
+	}
+	if !m.Ignore.Match(filepath.Join(root, "Movie", "movie.mkv"), false) {
+		t.Errorf("Update('D') did not record Movie/ in the ignore file")
+	}
+	if !strings.Contains(m.renderStatusBar(), "D: Ignore") {
+		t.Errorf("renderStatusBar() missing ignore hint when Ignore is set")
+	}
+}
+
 func TestDeleteFilesMode(t *testing.T) {
 	t.Parallel()
 	n := tuiTestNode("delete.nfo", false)
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/media"
+	"github.com/Digital-Shane/title-tidy/internal/trash"
+	"github.com/Digital-Shane/treeview"
+)
+
+// ResolveUpgrade executes a rename whose destination already exists, following the
+// decision recorded by the upgrade policy. A worse existing copy is disposed of
+// through bin, or renamed with mm.KeepSuffix when one is set; a worse incoming
+// copy is disposed of, or left where it is under its own name. Returns true when
+// the filesystem changed.
+func ResolveUpgrade(node *treeview.Node[treeview.FileInfo], mm *core.MediaMeta, bin trash.Bin) (bool, error) {
+	return resolveUpgradeAt(node, mm, mm.Destination(node.Data().Path), bin)
+}
+
+// resolveUpgradeAt is ResolveUpgrade for an explicit destination, used for the
+// children of virtual directories whose destination is below their parent.
+func resolveUpgradeAt(node *treeview.Node[treeview.FileInfo], mm *core.MediaMeta, dest string, bin trash.Bin) (bool, error) {
+	switch mm.Upgrade {
+	case core.UpgradeReplaceExisting:
+		if err := displaceFile(dest, mm.KeepSuffix, bin); err != nil {
+			return false, mm.Fail(fmt.Errorf("displace existing: %w", err))
+		}
+		if err := MovePath(node.Data().Path, dest); err != nil {
+			return false, mm.Fail(err)
+		}
+		mm.Success()
+		node.Data().Path = dest
+		return true, nil
+	case core.UpgradeDiscardIncoming:
+		if err := bin.Remove(node.Data().Path); err != nil {
+			return false, mm.Fail(err)
+		}
+		mm.Success()
+		return true, nil
+	case core.UpgradeKeepIncoming:
+		return false, nil
+	}
+	return RenameRegular(node, mm)
+}
+
+// displaceFile moves the existing file at path out of the way. With an empty
+// suffix the file goes to bin; otherwise it is renamed in place with the suffix
+// inserted before its extension.
+func displaceFile(path, suffix string, bin trash.Bin) error {
+	if suffix == "" {
+		return bin.Remove(path)
+	}
+	target := withSuffix(path, suffix)
+	if _, err := os.Stat(target); err == nil {
+		return fmt.Errorf("%s already exists", filepath.Base(target))
+	}
+	return os.Rename(path, target)
+}
+
+// withSuffix inserts suffix between the base name and extension of path.
+func withSuffix(path, suffix string) string {
+	ext := media.ExtractExtension(filepath.Base(path))
+	return path[:len(path)-len(ext)] + suffix + ext
+}
@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Digital-Shane/title-tidy/internal/core"
+	"github.com/Digital-Shane/title-tidy/internal/trash"
+)
+
+func TestResolveUpgrade(t *testing.T) {
+	tests := []struct {
+		name       string
+		decision   core.UpgradeDecision
+		suffix     string
+		wantFiles  map[string]string
+		wantAbsent []string
+		wantPath   string
+	}{
+		{
+			name:       "replace existing and delete it",
+			decision:   core.UpgradeReplaceExisting,
+			wantFiles:  map[string]string{"S01E02.mkv": "incoming"},
+			wantAbsent: []string{"incoming.mkv"},
+			wantPath:   "S01E02.mkv",
+		},
+		{
+			name:      "replace existing and keep it with suffix",
+			decision:  core.UpgradeReplaceExisting,
+			suffix:    ".old",
+			wantFiles: map[string]string{"S01E02.mkv": "incoming", "S01E02.old.mkv": "existing"},
+			wantPath:  "S01E02.mkv",
+		},
+		{
+			name:       "discard incoming",
+			decision:   core.UpgradeDiscardIncoming,
+			wantFiles:  map[string]string{"S01E02.mkv": "existing"},
+			wantAbsent: []string{"incoming.mkv"},
+			wantPath:   "incoming.mkv",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tmp := t.TempDir()
+			cwd, _ := os.Getwd()
+			defer os.Chdir(cwd)
+			os.Chdir(tmp)
+			os.WriteFile("S01E02.mkv", []byte("existing"), 0644)
+			os.WriteFile("incoming.mkv", []byte("incoming"), 0644)
+
+			n := fsTestNode("incoming.mkv", false, "incoming.mkv")
+			mm := core.EnsureMeta(n)
+			mm.NewName = "S01E02.mkv"
+			mm.Upgrade = tc.decision
+			mm.KeepSuffix = tc.suffix
+
+			if changed, err := ResolveUpgrade(n, mm, trash.Bin{Hard: true}); err != nil || !changed {
+				t.Fatalf("ResolveUpgrade() = (%v,%v), want (true,<nil>)", changed, err)
+			}
+			for f, want := range tc.wantFiles {
+				if data, err := os.ReadFile(f); err != nil || string(data) != want {
+					t.Errorf("ResolveUpgrade() %s = %q (%v), want %q", f, data, err, want)
+				}
+			}
+			for _, f := range tc.wantAbsent {
+				if _, err := os.Stat(f); !os.IsNotExist(err) {
+					t.Errorf("ResolveUpgrade() %s still exists", f)
+				}
+			}
+			if mm.RenameStatus != core.RenameStatusSuccess || n.Data().Path != tc.wantPath {
+				t.Errorf("ResolveUpgrade() status=%v path=%s, want success at %s", mm.RenameStatus, n.Data().Path, tc.wantPath)
+			}
+		})
+	}
+}
+
+func TestResolveUpgrade_SuffixTargetExists(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	for _, f := range []string{"S01E02.mkv", "S01E02.old.mkv", "incoming.mkv"} {
+		os.WriteFile(f, []byte(f), 0644)
+	}
+	n := fsTestNode("incoming.mkv", false, "incoming.mkv")
+	mm := core.EnsureMeta(n)
+	mm.NewName = "S01E02.mkv"
+	mm.Upgrade = core.UpgradeReplaceExisting
+	mm.KeepSuffix = ".old"
+	if changed, err := ResolveUpgrade(n, mm, trash.Bin{Hard: true}); err == nil || changed {
+		t.Errorf("ResolveUpgrade(suffix exists) = (%v,%v), want (false,error)", changed, err)
+	}
+	if mm.RenameStatus != core.RenameStatusError {
+		t.Errorf("ResolveUpgrade(suffix exists) status = %v, want error", mm.RenameStatus)
+	}
+}
+
+func TestResolveUpgrade_KeepIncoming(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(tmp)
+	os.WriteFile("S01E02.mkv", []byte("existing"), 0644)
+	os.WriteFile("incoming.mkv", []byte("incoming"), 0644)
+	n := fsTestNode("incoming.mkv", false, "incoming.mkv")
+	mm := core.EnsureMeta(n)
+	mm.NewName = "S01E02.mkv"
+	mm.Upgrade = core.UpgradeKeepIncoming
+	if changed, err := ResolveUpgrade(n, mm, trash.Bin{Hard: true}); err != nil || changed {
+		t.Errorf("ResolveUpgrade(keep incoming) = (%v,%v), want (false,<nil>)", changed, err)
+	}
+	for f, want := range map[string]string{"S01E02.mkv": "existing", "incoming.mkv": "incoming"} {
+		if data, err := os.ReadFile(f); err != nil || string(data) != want {
+			t.Errorf("ResolveUpgrade(keep incoming) %s = %q (%v), want %q", f, data, err, want)
+		}
+	}
+	if _, err := os.Stat("S01E02.old.mkv"); !os.IsNotExist(err) {
+		t.Errorf("ResolveUpgrade(keep incoming) created S01E02.old.mkv")
+	}
+}
@@ -0,0 +1,62 @@
+//go:build linux
+
+package watch
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// notifyMask covers arrivals and writes to top-level entries of the watched directory.
+const notifyMask = syscall.IN_CREATE | syscall.IN_MOVED_TO | syscall.IN_CLOSE_WRITE | syscall.IN_MODIFY | syscall.IN_ATTRIB
+
+// notify streams the names of entries in dir touched according to inotify. The
+// channel is closed when ctx is cancelled or the descriptor fails.
+func notify(ctx context.Context, dir string) (<-chan string, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+	if _, err := syscall.InotifyAddWatch(fd, dir, notifyMask); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("inotify_add_watch", err)
+	}
+	// A non-blocking descriptor is registered with the runtime poller, so
+	// closing the file unblocks the pending Read.
+	f := os.NewFile(uintptr(fd), "inotify")
+	go func() {
+		<-ctx.Done()
+		f.Close()
+	}()
+
+	out := make(chan string)
+	go func() {
+		defer close(out)
+		buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+		for {
+			n, err := f.Read(buf)
+			if err != nil {
+				return
+			}
+			for off := 0; off+syscall.SizeofInotifyEvent <= n; {
+				ev := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[off]))
+				start := off + syscall.SizeofInotifyEvent
+				end := start + int(ev.Len)
+				off = end
+				if ev.Len == 0 || end > n {
+					continue
+				}
+				name := string(bytes.TrimRight(buf[start:end], "\x00"))
+				select {
+				case out <- name:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package watch
+
+import (
+	"context"
+	"errors"
+)
+
+// notify is only implemented on Linux; other platforms poll.
+func notify(context.Context, string) (<-chan string, error) {
+	return nil, errors.New("inotify is only available on linux")
+}
@@ -0,0 +1,195 @@
+// Package watch monitors a download directory and hands newly arrived entries
+// to a callback once they have stopped changing.
+package watch
+
+import (
+	"context"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Options configures a watch loop.
+//
+// Fields:
+//   - Dir: directory whose top-level entries are watched.
+//   - Quiet: how long an entry's size and modification time must stay unchanged
+//     before it is handed off.
+//   - Interval: how often pending entries are re-examined, and how often the
+//     directory is listed when polling.
+//   - Poll: skip inotify and always list the directory on each interval.
+//   - Logger: receives lifecycle and batch messages; log.Default when nil.
+type Options struct {
+	Dir      string
+	Quiet    time.Duration
+	Interval time.Duration
+	Poll     bool
+	Logger   *log.Logger
+}
+
+// Handler processes a batch of stable entry names (relative to Options.Dir). It
+// returns the names the batch produced, such as renamed folders, so the watcher
+// does not pick its own output up as new arrivals, and the names it held back.
+// Held entries are offered again with the next batch.
+type Handler func(names []string) (produced, held []string, err error)
+
+// signature summarizes an entry so changes can be detected without reading content.
+type signature struct {
+	size    int64
+	files   int
+	modTime time.Time
+}
+
+// pending tracks an entry that has not been stable for long enough yet, or that
+// the handler held back.
+type pending struct {
+	sig     signature
+	changed time.Time
+	held    bool
+}
+
+// Run watches opts.Dir until ctx is cancelled. Entries present at startup are
+// ignored; later arrivals are passed to handle in batches once stable. Run
+// returns nil on cancellation.
+func Run(ctx context.Context, opts Options, handle Handler) error {
+	logger := opts.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+
+	known, err := list(opts.Dir)
+	if err != nil {
+		return err
+	}
+
+	var events <-chan string
+	if !opts.Poll {
+		ch, err := notify(ctx, opts.Dir)
+		if err != nil {
+			logger.Printf("inotify unavailable (%v), polling every %s", err, opts.Interval)
+		} else {
+			events = ch
+		}
+	}
+	logger.Printf("watching %s (quiet period %s)", opts.Dir, opts.Quiet)
+
+	queue := map[string]*pending{}
+	track := func(name string, now time.Time) {
+		if _, ok := known[name]; ok {
+			return
+		}
+		if _, ok := queue[name]; !ok {
+			queue[name] = &pending{changed: now}
+		}
+	}
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Printf("stopping watch of %s", opts.Dir)
+			return nil
+		case name, ok := <-events:
+			if !ok {
+				logger.Printf("inotify stopped, polling every %s", opts.Interval)
+				events = nil
+				continue
+			}
+			track(name, time.Now())
+		case now := <-ticker.C:
+			if events == nil {
+				current, err := list(opts.Dir)
+				if err != nil {
+					logger.Printf("list %s: %v", opts.Dir, err)
+					continue
+				}
+				for name := range current {
+					track(name, now)
+				}
+			}
+			ready := stable(opts.Dir, queue, now, opts.Quiet)
+			// Held entries only go out again along with a new arrival.
+			if !slices.ContainsFunc(ready, func(name string) bool { return !queue[name].held }) {
+				continue
+			}
+			logger.Printf("batch: %d new entries: %v", len(ready), ready)
+			produced, held, err := handle(ready)
+			if err != nil {
+				logger.Printf("batch failed: %v", err)
+			}
+			for _, name := range slices.Concat(ready, produced) {
+				if p, ok := queue[name]; ok && slices.Contains(held, name) {
+					p.held = true
+					continue
+				}
+				known[name] = struct{}{}
+				delete(queue, name)
+			}
+		}
+	}
+}
+
+// stable refreshes every queued entry's signature and returns, sorted, the names
+// that have not changed for at least quiet. Entries that vanished are dropped.
+func stable(dir string, queue map[string]*pending, now time.Time, quiet time.Duration) []string {
+	var ready []string
+	for name, p := range queue {
+		sig, err := signatureOf(filepath.Join(dir, name))
+		if err != nil {
+			delete(queue, name)
+			continue
+		}
+		if sig != p.sig {
+			p.sig, p.changed = sig, now
+			continue
+		}
+		if now.Sub(p.changed) >= quiet {
+			ready = append(ready, name)
+		}
+	}
+	slices.Sort(ready)
+	return ready
+}
+
+// signatureOf sums sizes and takes the newest modification time below path.
+func signatureOf(path string) (signature, error) {
+	var sig signature
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			sig.size += info.Size()
+			sig.files++
+		}
+		if info.ModTime().After(sig.modTime) {
+			sig.modTime = info.ModTime()
+		}
+		return nil
+	})
+	return sig, err
+}
+
+// list returns the names of the top-level entries in dir.
+func list(dir string) (map[string]struct{}, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]struct{}, len(entries))
+	for _, e := range entries {
+		names[e.Name()] = struct{}{}
+	}
+	return names, nil
+}
@@ -0,0 +1,139 @@
+package watch
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRunHandsOffStableNewEntries(t *testing.T) {
+	for _, poll := range []bool{true, false} {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "existing.mkv"), []byte("old"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		batches := make(chan []string, 4)
+		done := make(chan error, 1)
+		go func() {
+			done <- Run(ctx, Options{
+				Dir:      dir,
+				Quiet:    100 * time.Millisecond,
+				Interval: 20 * time.Millisecond,
+				Poll:     poll,
+				Logger:   log.New(io.Discard, "", 0),
+			}, func(names []string) ([]string, []string, error) {
+				batches <- names
+				return []string{"Renamed (2020)"}, nil, nil
+			})
+		}()
+
+		time.Sleep(50 * time.Millisecond) // let the watcher take its initial listing
+		os.Mkdir(filepath.Join(dir, "Movie.2020"), 0755)
+		os.WriteFile(filepath.Join(dir, "Movie.2020", "movie.mkv"), []byte("data"), 0644)
+
+		select {
+		case got := <-batches:
+			if !slices.Equal(got, []string{"Movie.2020"}) {
+				t.Errorf("Run(poll=%v) batch = %v, want [Movie.2020]", poll, got)
+			}
+		case <-ctx.Done():
+			t.Fatalf("Run(poll=%v) never handed off the new entry", poll)
+		}
+
+		// The handler's own output must not be treated as a new arrival.
+		os.Rename(filepath.Join(dir, "Movie.2020"), filepath.Join(dir, "Renamed (2020)"))
+		select {
+		case got := <-batches:
+			t.Errorf("Run(poll=%v) reprocessed its own output: %v", poll, got)
+		case <-time.After(300 * time.Millisecond):
+		}
+
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Run(poll=%v) = %v, want nil on cancellation", poll, err)
+		}
+	}
+}
+
+func TestRunOffersHeldEntriesWithTheNextBatch(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	batches := make(chan []string, 4)
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(ctx, Options{
+			Dir:      dir,
+			Quiet:    100 * time.Millisecond,
+			Interval: 20 * time.Millisecond,
+			Poll:     true,
+			Logger:   log.New(io.Discard, "", 0),
+		}, func(names []string) ([]string, []string, error) {
+			batches <- names
+			if len(names) == 1 {
+				return nil, names, nil
+			}
+			return nil, nil, nil
+		})
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	os.WriteFile(filepath.Join(dir, "Movie.2020.en.srt"), []byte("sub"), 0644)
+	select {
+	case got := <-batches:
+		if !slices.Equal(got, []string{"Movie.2020.en.srt"}) {
+			t.Fatalf("Run() batch = %v, want [Movie.2020.en.srt]", got)
+		}
+	case <-ctx.Done():
+		t.Fatal("Run() never handed off the subtitle")
+	}
+	// A held entry alone does not make a batch.
+	select {
+	case got := <-batches:
+		t.Fatalf("Run() offered a held entry on its own: %v", got)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	os.WriteFile(filepath.Join(dir, "Movie.2020.mkv"), []byte("video"), 0644)
+	select {
+	case got := <-batches:
+		if want := []string{"Movie.2020.en.srt", "Movie.2020.mkv"}; !slices.Equal(got, want) {
+			t.Errorf("Run() batch = %v, want %v", got, want)
+		}
+	case <-ctx.Done():
+		t.Fatal("Run() never offered the held entry again")
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Run() = %v, want nil on cancellation", err)
+	}
+}
+
+func TestStableWaitsForQuietPeriod(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "dl.mkv")
+	os.WriteFile(path, []byte("a"), 0644)
+	queue := map[string]*pending{"dl.mkv": {}, "gone.mkv": {}}
+	start := time.Now()
+
+	if got := stable(dir, queue, start, time.Minute); len(got) != 0 {
+		t.Errorf("stable(first sighting) = %v, want none", got)
+	}
+	if _, ok := queue["gone.mkv"]; ok {
+		t.Errorf("stable kept an entry that no longer exists")
+	}
+	os.WriteFile(path, []byte("ab"), 0644)
+	if got := stable(dir, queue, start.Add(2*time.Minute), time.Minute); len(got) != 0 {
+		t.Errorf("stable(growing) = %v, want none", got)
+	}
+	if got := stable(dir, queue, start.Add(4*time.Minute), time.Minute); !slices.Equal(got, []string{"dl.mkv"}) {
+		t.Errorf("stable(quiet) = %v, want [dl.mkv]", got)
+	}
+}
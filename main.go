@@ -1,12 +1,20 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"slices"
+	"syscall"
+	"time"
 
 	"github.com/Digital-Shane/title-tidy/internal/cmd"
+	"github.com/Digital-Shane/title-tidy/internal/ignore"
+	"github.com/Digital-Shane/title-tidy/internal/media"
 )
 
 func main() {
@@ -21,6 +29,7 @@ func main() {
 		"seasons":  cmd.SeasonsCommand,
 		"episodes": cmd.EpisodesCommand,
 		"movies":   cmd.MoviesCommand,
+		"auto":     cmd.AutoCommand,
 	}
 	helpKeywords := []string{"help", "--help", "-h"}
 
@@ -30,6 +39,18 @@ func main() {
 		return
 	}
 
+	// Watch a download directory with one of the rename commands
+	if command == "watch" {
+		runWatch(configs, os.Args[2:])
+		return
+	}
+
+	// Rename a completed download into the library (download client hooks)
+	if command == "ingest" {
+		runIngest(os.Args[2:])
+		return
+	}
+
 	// Run a rename command
 	cfg, ok := configs[command]
 	if !ok {
@@ -37,16 +58,149 @@ func main() {
 		printUsage()
 		os.Exit(1)
 	}
+	cfg = parseConfig(command, cfg, os.Args[2:], nil)
+
+	if err := cmd.RunCommand(cfg); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runWatch handles `title-tidy watch <mode> <dir> [options]`.
+func runWatch(configs map[string]cmd.CommandConfig, args []string) {
+	if len(args) < 2 {
+		fmt.Printf("Usage: title-tidy watch <shows|seasons|episodes|movies> <dir> [options]\n\n")
+		printUsage()
+		os.Exit(1)
+	}
+	cfg, ok := configs[args[0]]
+	if !ok {
+		fmt.Printf("Unknown watch mode: %s\n\n", args[0])
+		printUsage()
+		os.Exit(1)
+	}
+	wc := cmd.WatchConfig{Dir: args[1]}
+	cfg = parseConfig("watch", cfg, args[2:], func(flags *flag.FlagSet) {
+		flags.DurationVar(&wc.Quiet, "quiet", cmd.DefaultQuietPeriod, "Wait until new entries are unchanged for this long")
+		flags.DurationVar(&wc.Interval, "interval", 5*time.Second, "How often pending entries are checked")
+		flags.BoolVar(&wc.Poll, "poll", false, "Poll the directory instead of using inotify")
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := cmd.RunWatch(ctx, cfg, wc); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runIngest handles `title-tidy ingest <path> [options]`. The result is printed
+// as JSON and the process exits with the code matching the outcome.
+func runIngest(args []string) {
+	if len(args) < 1 {
+		fmt.Printf("Usage: title-tidy ingest <path> --movies <dir> --shows <dir> [options]\n\n")
+		printUsage()
+		os.Exit(cmd.IngestFailed)
+	}
+	ic := cmd.IngestConfig{Path: args[0]}
+	var copyFiles, linkFiles bool
+	cfg := parseConfig("ingest", cmd.CommandConfig{}, args[1:], func(flags *flag.FlagSet) {
+		flags.StringVar(&ic.Movies, "movies", os.Getenv("TITLE_TIDY_MOVIES"), "Movie library root (default $TITLE_TIDY_MOVIES)")
+		flags.StringVar(&ic.Shows, "shows", os.Getenv("TITLE_TIDY_SHOWS"), "TV library root (default $TITLE_TIDY_SHOWS)")
+		flags.BoolVar(&copyFiles, "copy", false, "Copy the download into the library instead of moving it")
+		flags.BoolVar(&linkFiles, "link", false, "Hard link the download into the library instead of moving it")
+	})
+	switch {
+	case copyFiles && linkFiles:
+		fmt.Printf("Error: --copy and --link cannot be combined\n")
+		os.Exit(cmd.IngestFailed)
+	case copyFiles:
+		ic.Transfer = cmd.TransferCopy
+	case linkFiles:
+		ic.Transfer = cmd.TransferLink
+	}
+
+	result, err := cmd.RunIngest(cfg, ic)
+	code := result.ExitCode()
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		code = cmd.IngestFailed
+	}
+	out, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(out))
+	os.Exit(code)
+}
 
+// parseConfig applies the shared rename flags in args to cfg. extra registers
+// command-specific flags on the same set. Invalid flags exit with status 1, which
+// is also IngestFailed, so download client hooks never mistake them for status 2
+// (IngestUnrecognized).
+func parseConfig(command string, cfg cmd.CommandConfig, args []string, extra func(*flag.FlagSet)) cmd.CommandConfig {
 	// Parse flags for the command
-	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
 	instant := flags.Bool("i", false, "Apply renames immediately without interactive preview")
 	flags.BoolVar(instant, "instant", false, "Apply renames immediately without interactive preview")
 	noNFO := flags.Bool("no-nfo", false, "Delete NFO files during rename")
 	noImages := flags.Bool("no-img", false, "Delete image files during rename")
+	upgrade := flags.String("upgrade", "off", "Resolve renames onto existing files by quality: off, delete or suffix")
+	extras := flags.String("extras", "folder", "Place trailers, featurettes and other extras: folder, suffix or off")
+	upgradeSize := flags.Bool("upgrade-size", false, "Use file size to break quality ties when upgrading")
+	hardDelete := flags.Bool("hard-delete", false, "Permanently delete files instead of moving them to the trash")
+	quarantine := flags.String("quarantine", "", "Move deleted files into this directory instead of the trash")
+	noSample := flags.Bool("no-sample", false, "Delete release sample videos during rename")
+	sampleRatio := flags.Float64("sample-ratio", 0, "Treat videos smaller than this fraction of the main movie video as samples (0, the default, disables)")
+	junk := flags.Bool("junk", false, "Delete release clutter: samples, .txt/.url/.exe, Screens/ and empty Subs/ folders")
+	keepEmpty := flags.Bool("keep-empty", false, "Keep directories left empty by the rename")
+	pruneEmpty := flags.Bool("prune-empty", false, "Also remove directories that were already empty")
+	utf8 := flags.Bool("utf8", false, "Convert Windows-1252/1251 subtitles to UTF-8, keeping a .bak backup")
+	collections := flags.Bool("collections", false, "Group movies of a collection (NFO <set> tag) into a collection folder")
+	collectionMap := flags.String("collection-map", "", "File assigning movies to collections, one \"movie = collection\" per line (implies --collections)")
+	depth := flags.Int("depth", cmd.DefaultScanDepth, "Directory levels to scan in shows, seasons and auto modes")
+	if spec := os.Getenv("TITLE_TIDY_EXTENSIONS"); spec != "" {
+		if err := media.ApplyExtensionSpec(spec); err != nil {
+			fmt.Printf("Error: TITLE_TIDY_EXTENSIONS: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	flags.Func("sub-lang", "Write subtitle languages as keep, 639-1 or 639-2", func(s string) error {
+		form, err := media.ParseLanguageForm(s)
+		if err == nil {
+			media.SetLanguageForm(form)
+		}
+		return err
+	})
+	flags.Func("edition", "Write movie editions as plex, jellyfin or off", func(s string) error {
+		style, err := media.ParseEditionStyle(s)
+		if err == nil {
+			media.SetEditionStyle(style)
+		}
+		return err
+	})
+	flags.Func("title-case", "Case titles as off, on or sort (on with leading articles moved)", func(s string) error {
+		style, err := media.ParseTitleCaseStyle(s)
+		if err == nil {
+			media.SetTitleCaseStyle(style)
+		}
+		return err
+	})
+	flags.Func("ext", "Assign extensions to a file category, e.g. video=f4v,mxf or none=ts (repeatable)", media.ApplyExtensionSpec)
+	var rules []cmd.DeleteRule
+	flags.Func("delete", "Delete entries matching a rule, e.g. glob=*.txt or re=sample,type=video,max=100MB (repeatable)", func(spec string) error {
+		r, err := cmd.ParseDeleteRule(spec)
+		if err == nil {
+			rules = append(rules, r)
+		}
+		return err
+	})
+
+	if extra != nil {
+		extra(flags)
+	}
 
 	// Parse remaining arguments after the command
-	if err := flags.Parse(os.Args[2:]); err != nil {
+	if err := flags.Parse(args); errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	} else if err != nil {
 		fmt.Printf("Error parsing flags: %v\n", err)
 		os.Exit(1)
 	}
@@ -55,11 +209,41 @@ func main() {
 	cfg.InstantMode = *instant
 	cfg.DeleteNFO = *noNFO
 	cfg.DeleteImages = *noImages
-
-	if err := cmd.RunCommand(cfg); err != nil {
+	cfg.UpgradeBySize = *upgradeSize
+	cfg.HardDelete = *hardDelete
+	cfg.Quarantine = *quarantine
+	if *junk {
+		rules = append(rules, cmd.JunkRules...)
+	}
+	cfg.DeleteRules = rules
+	cfg.DeleteSamples = *noSample || *junk
+	cfg.SampleRatio = *sampleRatio
+	cfg.KeepEmptyDirs = *keepEmpty
+	cfg.PruneEmptyDirs = *pruneEmpty
+	cfg.Depth = *depth
+	cfg.ConvertUTF8 = *utf8
+	cfg.Collections = *collections || *collectionMap != ""
+	if *collectionMap != "" {
+		m, err := cmd.LoadCollectionMap(*collectionMap)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.CollectionMap = m
+	}
+	policy, err := cmd.ParseUpgradePolicy(*upgrade)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.Upgrade = policy
+	extrasPolicy, err := cmd.ParseExtrasPolicy(*extras)
+	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
+	cfg.Extras = extrasPolicy
+	return cfg
 }
 
 func printUsage() {
@@ -67,11 +251,47 @@ func printUsage() {
 	fmt.Printf("Usage:\n")
 	fmt.Printf("  title-tidy shows     Rename TV show files and folders\n")
 	fmt.Printf("  title-tidy seasons   Rename season folders and episodes within\n")
-	fmt.Printf("  title-tidy episodes  Rename episode files in current directory\n")
+	fmt.Printf("  title-tidy episodes  Rename episode files, grouping them by show and season\n")
 	fmt.Printf("  title-tidy movies    Rename movie files and folders\n")
+	fmt.Printf("  title-tidy auto      Detect the right mode for each entry (press m to change it)\n")
+	fmt.Printf("  title-tidy watch <mode> <dir>  Rename new downloads in <dir> as they finish\n")
+	fmt.Printf("  title-tidy ingest <path>       Rename a finished download into your library (JSON output)\n")
 	fmt.Printf("  title-tidy help      Show this help message\n\n")
 	fmt.Printf("Options:\n")
 	fmt.Printf("  -i, --instant          Apply renames immediately and exit\n")
 	fmt.Printf("  --no-nfo               Delete NFO files during rename\n")
 	fmt.Printf("  --no-img               Delete image files during rename\n")
+	fmt.Printf("  --upgrade <policy>     Keep the better copy when a file already exists: off, delete, suffix\n")
+	fmt.Printf("  --upgrade-size         Use file size to break quality ties when upgrading\n")
+	fmt.Printf("  --hard-delete          Permanently delete files instead of using the trash\n")
+	fmt.Printf("  --quarantine <dir>     Move deleted files into <dir> instead of the trash\n")
+	fmt.Printf("  --no-sample            Delete release sample videos during rename\n")
+	fmt.Printf("  --sample-ratio <f>     Videos smaller than f times the main movie video are samples (off by default, e.g. 0.1)\n")
+	fmt.Printf("  --junk                 Delete samples, .txt/.url/.exe files, Screens/ and empty Subs/ folders\n")
+	fmt.Printf("  --keep-empty           Keep directories left empty by the rename\n")
+	fmt.Printf("  --prune-empty          Also remove directories that were already empty\n")
+	fmt.Printf("  --ext <cat>=<exts>     Treat extensions as video, subtitle, audio, sidecar, nfo, image or none\n")
+	fmt.Printf("                           (repeatable; defaults from $TITLE_TIDY_EXTENSIONS, specs joined by ;)\n")
+	fmt.Printf("  --utf8                 Convert Windows-1252/1251 subtitles to UTF-8, keeping a .bak backup\n")
+	fmt.Printf("  --sub-lang <form>      Subtitle language codes: keep (default), 639-1 (en) or 639-2 (eng)\n")
+	fmt.Printf("  --edition <style>      Movie editions: plex (default, {edition-Final Cut}), jellyfin (- Final Cut) or off\n")
+	fmt.Printf("  --title-case <mode>    Title casing: off (default), on (The Office (US), S.W.A.T.) or sort (Office, The (US))\n")
+	fmt.Printf("  --extras <policy>      Extras: folder (default, Trailers/, Featurettes/...), suffix (-trailer) or off\n")
+	fmt.Printf("  --collections          Move movies of a collection (NFO <set> tag) into \"<Collection>/\"\n")
+	fmt.Printf("  --collection-map <f>   Assign movies to collections, one \"The Matrix (1999) = The Matrix Collection\" per line\n")
+	fmt.Printf("  --depth <n>            Directory levels scanned by shows, seasons and auto (default %d)\n", cmd.DefaultScanDepth)
+	fmt.Printf("  --delete <rule>        Delete entries matching a rule (repeatable), e.g.:\n")
+	fmt.Printf("                           glob=*.txt  re=(?i)sample,type=video,max=100MB  glob=Extras,dir\n\n")
+	fmt.Printf("Entries matching gitignore-style patterns in %s files are never indexed.\n\n", ignore.FileName)
+	fmt.Printf("Watch options:\n")
+	fmt.Printf("  --quiet <duration>     Wait until new entries are unchanged for this long (default %s)\n", cmd.DefaultQuietPeriod)
+	fmt.Printf("  --interval <duration>  How often pending entries are checked (default 5s)\n")
+	fmt.Printf("  --poll                 Poll the directory instead of using inotify\n\n")
+	fmt.Printf("Ingest options:\n")
+	fmt.Printf("  --movies <dir>         Movie library root (default $TITLE_TIDY_MOVIES)\n")
+	fmt.Printf("  --shows <dir>          TV library root (default $TITLE_TIDY_SHOWS)\n")
+	fmt.Printf("  --copy                 Copy the download instead of moving it, so torrents keep seeding\n")
+	fmt.Printf("  --link                 Hard link the download instead of moving it (copies across filesystems)\n")
+	fmt.Printf("  Without --copy or --link the download is moved into the library.\n")
+	fmt.Printf("  Exit codes: 0 done, 1 failed, 2 no media found, 3 finished with errors\n")
 }